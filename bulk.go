@@ -0,0 +1,103 @@
+package flexsql
+
+import (
+	"errors"
+	"reflect"
+	"strconv"
+)
+
+var (
+	ErrRowsMustBeSlice = errors.New("rows must be slice")
+	ErrRowMustBeStruct = errors.New("row must be struct or pointer to struct")
+	ErrTooManyColumns  = errors.New("Too many columns")
+)
+
+// InsertBatch is a statement generated by BulkInsert
+// together with the values of its placeholders.
+type InsertBatch struct {
+	InsertStmt *InsertStmt
+	Params     map[string]interface{}
+}
+
+// BulkInsert generates multi-row INSERT statements inserting rows,
+// which must be a slice of structs or pointers to structs.
+//
+// Like Mapper, the column names are the names of the exported fields.
+// The placeholder of field F of the n-th row of a statement is F_n.
+// If the Dialect is a ParameterLimiter, rows are split into
+// as many statements as needed to stay within the limit.
+//
+// Example
+//
+//	type User struct {
+//		ID   int
+//		Name string
+//	}
+//	batches, _ := BulkInsert(&Postgres{}, &Table{Name: "user"}, users)
+//	for _, batch := range batches {
+//		query, _ := compiler.Compile(batch.InsertStmt)
+//		args, _ := query.Bind(batch.Params)
+//		_, _ = db.Exec(query.SQL(), args...)
+//	}
+func BulkInsert(d Dialect, table *Table, rows interface{}) ([]*InsertBatch, error) {
+	value := reflect.ValueOf(rows)
+	if value.Kind() != reflect.Slice {
+		return nil, ErrRowsMustBeSlice
+	}
+	if value.Len() <= 0 {
+		return nil, ErrZeroLength
+	}
+
+	rowType := value.Type().Elem()
+	if rowType.Kind() == reflect.Ptr {
+		rowType = rowType.Elem()
+	}
+	if rowType.Kind() != reflect.Struct {
+		return nil, ErrRowMustBeStruct
+	}
+
+	columns, indices := exportedFields(rowType)
+	if len(columns) <= 0 {
+		return nil, ErrZeroLength
+	}
+
+	rowsPerBatch := value.Len()
+	if limiter, ok := d.(ParameterLimiter); ok {
+		rowsPerBatch = limiter.MaxParameters() / len(columns)
+		if rowsPerBatch <= 0 {
+			return nil, ErrTooManyColumns
+		}
+	}
+
+	var batches []*InsertBatch
+	for start := 0; start < value.Len(); start += rowsPerBatch {
+		end := start + rowsPerBatch
+		if end > value.Len() {
+			end = value.Len()
+		}
+		batch := &InsertBatch{
+			InsertStmt: &InsertStmt{
+				Table:   table,
+				Columns: columns,
+				Values:  make([][]Expr, end-start),
+			},
+			Params: make(map[string]interface{}, (end-start)*len(columns)),
+		}
+		for i := start; i < end; i++ {
+			row := reflect.Indirect(value.Index(i))
+			if !row.IsValid() {
+				return nil, ErrRowMustBeStruct
+			}
+			suffix := "_" + strconv.Itoa(i-start+1)
+			exprs := make([]Expr, len(columns))
+			for j, column := range columns {
+				name := column + suffix
+				exprs[j] = Placeholder(name)
+				batch.Params[name] = row.Field(indices[j]).Interface()
+			}
+			batch.InsertStmt.Values[i-start] = exprs
+		}
+		batches = append(batches, batch)
+	}
+	return batches, nil
+}
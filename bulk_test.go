@@ -0,0 +1,113 @@
+package flexsql
+
+import (
+	"testing"
+)
+
+type bulkTestRow struct {
+	ID      int
+	Name    string
+	private bool
+}
+
+// smallLimitDialect is Postgres allowing only 5 parameters per statement.
+type smallLimitDialect struct {
+	Postgres
+}
+
+func (d *smallLimitDialect) MaxParameters() int {
+	return 5
+}
+
+func TestBulkInsert(t *testing.T) {
+	table := &Table{Name: "t"}
+	rows := []bulkTestRow{
+		{1, "a", false},
+		{2, "b", false},
+	}
+
+	batches, err := BulkInsert(&Postgres{}, table, rows)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	testEqual(t, len(batches), 1)
+
+	c := NewCompiler(&Postgres{})
+	query, err := c.Compile(batches[0].InsertStmt)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	testEqual(t, query.SQL(), `INSERT INTO "t" ("ID","Name") VALUES ($1,$2),($3,$4)`)
+	params, err := query.Bind(batches[0].Params)
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	testDeepEqual(t, params, []interface{}{1, "a", 2, "b"})
+
+	// Pointers to structs are accepted as well.
+	batches, err = BulkInsert(&Postgres{}, table, []*bulkTestRow{&rows[1]})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	testDeepEqual(t, batches[0].Params, map[string]interface{}{"ID_1": 2, "Name_1": "b"})
+}
+
+func TestBulkInsertSplit(t *testing.T) {
+	rows := []bulkTestRow{
+		{1, "a", false},
+		{2, "b", false},
+		{3, "c", false},
+		{4, "d", false},
+		{5, "e", false},
+	}
+	batches, err := BulkInsert(&smallLimitDialect{}, &Table{Name: "t"}, rows)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	testEqual(t, len(batches), 3)
+
+	c := NewCompiler(&smallLimitDialect{})
+	expected := []struct {
+		sql    string
+		params []interface{}
+	}{
+		{`INSERT INTO "t" ("ID","Name") VALUES ($1,$2),($3,$4)`, []interface{}{1, "a", 2, "b"}},
+		{`INSERT INTO "t" ("ID","Name") VALUES ($1,$2),($3,$4)`, []interface{}{3, "c", 4, "d"}},
+		{`INSERT INTO "t" ("ID","Name") VALUES ($1,$2)`, []interface{}{5, "e"}},
+	}
+	for i, batch := range batches {
+		query, err := c.Compile(batch.InsertStmt)
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+			continue
+		}
+		testEqual(t, query.SQL(), expected[i].sql)
+		params, err := query.Bind(batch.Params)
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+		testDeepEqual(t, params, expected[i].params)
+	}
+}
+
+func TestBulkInsertError(t *testing.T) {
+	table := &Table{Name: "t"}
+	cases := []struct {
+		rows interface{}
+		err  error
+	}{
+		{bulkTestRow{}, ErrRowsMustBeSlice},
+		{[]bulkTestRow{}, ErrZeroLength},
+		{[]int{1}, ErrRowMustBeStruct},
+		{[]*bulkTestRow{nil}, ErrRowMustBeStruct},
+		{[]struct{ a int }{{1}}, ErrZeroLength},
+	}
+	for _, case_ := range cases {
+		_, err := BulkInsert(&Postgres{}, table, case_.rows)
+		testEqual(t, err, case_.err)
+	}
+
+	type wide struct{ A, B, C, D, E, F int }
+	_, err := BulkInsert(&smallLimitDialect{}, table, []wide{{}})
+	testEqual(t, err, ErrTooManyColumns)
+}
@@ -0,0 +1,217 @@
+package flexsql
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"hash"
+	"reflect"
+	"sort"
+	"sync"
+)
+
+type cacheKey [sha256.Size]byte
+
+type cacheEntry struct {
+	key   cacheKey
+	query *CompiledQuery
+}
+
+// CacheStats reports the activity of a Cache.
+type CacheStats struct {
+	Hits      uint64
+	Misses    uint64
+	Evictions uint64
+	Size      int
+}
+
+// Cache caches CompiledQuery by the structure of the compiled Node,
+// the Dialect and the options of the Compiler.
+// The least recently used entry is evicted when the cache is full.
+//
+// Cache is safe for concurrent use.
+//
+// Example
+//
+//	cache := NewCache(1024)
+//	compiler := NewCompiler(&Postgres{})
+//	query, err := cache.Compile(compiler, buildSelectStmt())
+type Cache struct {
+	mutex    sync.Mutex
+	capacity int
+	ll       *list.List
+	entries  map[cacheKey]*list.Element
+	stats    CacheStats
+}
+
+// NewCache returns a Cache holding at most capacity queries.
+func NewCache(capacity int) *Cache {
+	if capacity <= 0 {
+		panic(fmt.Sprintf("illegal cache capacity: %v", capacity))
+	}
+	return &Cache{
+		capacity: capacity,
+		ll:       list.New(),
+		entries:  make(map[cacheKey]*list.Element),
+	}
+}
+
+// Compile is like c.Compile(e) but returns the cached result
+// if a structurally identical Node has been compiled with
+// an equivalent Compiler before.
+func (cache *Cache) Compile(c *Compiler, e Node) (*CompiledQuery, error) {
+	key := fingerprint(c.compilerConfig, e)
+
+	if query, ok := cache.get(key); ok {
+		return query, nil
+	}
+
+	query, err := c.Compile(e)
+	if err != nil {
+		return nil, err
+	}
+	cache.put(key, query)
+	return query, nil
+}
+
+// Stats returns a snapshot of the statistics of the cache.
+func (cache *Cache) Stats() CacheStats {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+	stats := cache.stats
+	stats.Size = cache.ll.Len()
+	return stats
+}
+
+func (cache *Cache) get(key cacheKey) (*CompiledQuery, bool) {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+	elem, ok := cache.entries[key]
+	if !ok {
+		cache.stats.Misses += 1
+		return nil, false
+	}
+	cache.stats.Hits += 1
+	cache.ll.MoveToFront(elem)
+	return elem.Value.(*cacheEntry).query, true
+}
+
+func (cache *Cache) put(key cacheKey, query *CompiledQuery) {
+	cache.mutex.Lock()
+	defer cache.mutex.Unlock()
+	// Another goroutine may have compiled the same query meanwhile.
+	if elem, ok := cache.entries[key]; ok {
+		cache.ll.MoveToFront(elem)
+		return
+	}
+	cache.entries[key] = cache.ll.PushFront(&cacheEntry{key, query})
+	for cache.ll.Len() > cache.capacity {
+		oldest := cache.ll.Back()
+		cache.ll.Remove(oldest)
+		delete(cache.entries, oldest.Value.(*cacheEntry).key)
+		cache.stats.Evictions += 1
+	}
+}
+
+// fingerprint computes a structural fingerprint of values.
+// Pointers are followed so two separately constructed but
+// identical trees have the same fingerprint.
+func fingerprint(values ...interface{}) cacheKey {
+	f := &fingerprinter{
+		hash:     sha256.New(),
+		visiting: make(map[uintptr]bool),
+	}
+	for _, v := range values {
+		f.write(reflect.ValueOf(v))
+	}
+	var key cacheKey
+	copy(key[:], f.hash.Sum(nil))
+	return key
+}
+
+type fingerprinter struct {
+	hash     hash.Hash
+	visiting map[uintptr]bool
+}
+
+func (f *fingerprinter) writeUint(u uint64) {
+	var buf [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(buf[:], u)
+	f.hash.Write(buf[:n])
+}
+
+func (f *fingerprinter) writeString(s string) {
+	f.writeUint(uint64(len(s)))
+	f.hash.Write([]byte(s))
+}
+
+func (f *fingerprinter) writeType(t reflect.Type) {
+	f.writeString(t.PkgPath())
+	f.writeString(t.String())
+}
+
+func (f *fingerprinter) write(v reflect.Value) {
+	if !v.IsValid() {
+		f.writeString("")
+		return
+	}
+	f.writeType(v.Type())
+	switch v.Kind() {
+	case reflect.Bool:
+		if v.Bool() {
+			f.writeUint(1)
+		} else {
+			f.writeUint(0)
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		f.writeUint(uint64(v.Int()))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		f.writeUint(v.Uint())
+	case reflect.Float32, reflect.Float64:
+		f.writeString(fmt.Sprint(v.Float()))
+	case reflect.Complex64, reflect.Complex128:
+		f.writeString(fmt.Sprint(v.Complex()))
+	case reflect.String:
+		f.writeString(v.String())
+	case reflect.Ptr:
+		if v.IsNil() {
+			f.writeUint(0)
+			return
+		}
+		f.writeUint(1)
+		addr := v.Pointer()
+		if f.visiting[addr] {
+			panic("cyclic node")
+		}
+		f.visiting[addr] = true
+		f.write(v.Elem())
+		delete(f.visiting, addr)
+	case reflect.Interface:
+		f.write(v.Elem())
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			f.writeString(v.Type().Field(i).Name)
+			f.write(v.Field(i))
+		}
+	case reflect.Slice, reflect.Array:
+		f.writeUint(uint64(v.Len()))
+		for i := 0; i < v.Len(); i++ {
+			f.write(v.Index(i))
+		}
+	case reflect.Map:
+		keys := v.MapKeys()
+		sort.Slice(keys, func(i, j int) bool {
+			return fmt.Sprint(keys[i]) < fmt.Sprint(keys[j])
+		})
+		f.writeUint(uint64(len(keys)))
+		for _, k := range keys {
+			f.write(k)
+			f.write(v.MapIndex(k))
+		}
+	default:
+		// Functions, channels and unsafe pointers
+		// can only be compared by identity.
+		f.writeUint(uint64(v.Pointer()))
+	}
+}
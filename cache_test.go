@@ -0,0 +1,101 @@
+package flexsql
+
+import (
+	"sync"
+	"testing"
+)
+
+func buildCacheTestStmt(label string) *SelectStmt {
+	return &SelectStmt{
+		Columns: []*LabeledColumn{
+			&LabeledColumn{&Column{"t", "a"}, label},
+		},
+		FromClause: &FromClause{&FromClauseItem{
+			TableRef: &LabeledTable{Name: "t", Label: "t"},
+		}},
+		WhereClause: &WhereClause{Eq(&Column{"t", "a"}, Placeholder("a"))},
+	}
+}
+
+func TestFingerprint(t *testing.T) {
+	a := fingerprint(buildCacheTestStmt("a"))
+	testEqual(t, a, fingerprint(buildCacheTestStmt("a")))
+	if a == fingerprint(buildCacheTestStmt("b")) {
+		t.Errorf("different trees have the same fingerprint")
+	}
+	if fingerprint(literal("a")) == fingerprint(Placeholder("a")) {
+		t.Errorf("different node types have the same fingerprint")
+	}
+	if fingerprint(MakeTuple(literal("ab"))) == fingerprint(MakeTuple(literal("a"), literal("b"))) {
+		t.Errorf("ambiguous fingerprint")
+	}
+}
+
+func TestCache(t *testing.T) {
+	cache := NewCache(2)
+	c := NewCompiler(&Postgres{})
+
+	q1, err := cache.Compile(c, buildCacheTestStmt("a"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	testEqual(t, q1.SQL(), `SELECT "t"."a" "a" FROM "t" "t" WHERE "t"."a" = $1`)
+
+	q2, err := cache.Compile(c, buildCacheTestStmt("a"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	testEqual(t, q2, q1)
+	testEqual(t, cache.Stats(), CacheStats{Hits: 1, Misses: 1, Size: 1})
+
+	pretty := NewCompiler(&Postgres{}, WithPrettyPrint())
+	q3, err := cache.Compile(pretty, buildCacheTestStmt("a"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if q3 == q1 {
+		t.Errorf("compiler options must be part of the cache key")
+	}
+	testEqual(t, cache.Stats(), CacheStats{Hits: 1, Misses: 2, Size: 2})
+
+	// "a" with the default compiler is now the least recently used entry.
+	if _, err := cache.Compile(c, buildCacheTestStmt("b")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	testEqual(t, cache.Stats(), CacheStats{Hits: 1, Misses: 3, Evictions: 1, Size: 2})
+	if _, err := cache.Compile(pretty, buildCacheTestStmt("a")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	testEqual(t, cache.Stats(), CacheStats{Hits: 2, Misses: 3, Evictions: 1, Size: 2})
+}
+
+func TestCacheConcurrent(t *testing.T) {
+	cache := NewCache(8)
+	c := NewCompiler(&Postgres{})
+	shared := buildCacheTestStmt("a")
+
+	var wg sync.WaitGroup
+	for i := 0; i < 16; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				q, err := cache.Compile(c, shared)
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+					return
+				}
+				testEqual(t, q.SQL(), `SELECT "t"."a" "a" FROM "t" "t" WHERE "t"."a" = $1`)
+			}
+		}()
+	}
+	wg.Wait()
+
+	stats := cache.Stats()
+	testEqual(t, stats.Hits+stats.Misses, uint64(1600))
+	testEqual(t, stats.Size, 1)
+}
+
+func TestCachePanic(t *testing.T) {
+	testPanic(t, func() { NewCache(0) }, "illegal cache capacity: 0")
+}
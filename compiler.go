@@ -2,14 +2,142 @@ package flexsql
 
 import (
 	"bytes"
+	"database/sql"
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
 )
 
+var (
+	ErrIllegalPlaceholderName = errors.New("Illegal placeholder name")
+	ErrSliceLengthMismatch    = errors.New("Slice length mismatch")
+	ErrPositionalPlaceholders = errors.New("Placeholders are positional")
+	ErrSliceLengthConflict    = errors.New("Conflicting slice lengths")
+)
+
+var placeholderNameRegexp = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+
+// PlaceholderStyle controls how placeholders are rendered.
+type PlaceholderStyle uint
+
+const (
+	// PlaceholderDialect delegates to Dialect.MakePlaceholder.
+	PlaceholderDialect PlaceholderStyle = iota
+	// PlaceholderQuestion renders every placeholder as ?.
+	PlaceholderQuestion
+	// PlaceholderDollar renders placeholders as $1, $2 and so on.
+	PlaceholderDollar
+	// PlaceholderColonName renders placeholders as :name.
+	PlaceholderColonName
+	// PlaceholderAtName renders placeholders as @name.
+	PlaceholderAtName
+	// PlaceholderDollarName renders placeholders as $name.
+	PlaceholderDollarName
+)
+
+// named reports whether the style refers to placeholders by name.
+// Every occurrence of a named placeholder is bound to the same argument.
+func (s PlaceholderStyle) named() bool {
+	switch s {
+	case PlaceholderColonName, PlaceholderAtName, PlaceholderDollarName:
+		return true
+	}
+	return false
+}
+
+// CompilerOption configures a Compiler constructed by NewCompiler.
+type CompilerOption func(c *Compiler)
+
+// WithPrettyPrint puts each clause of a statement on its own line.
+func WithPrettyPrint() CompilerOption {
+	return func(c *Compiler) {
+		c.prettyPrint = true
+	}
+}
+
+// WithPlaceholderStyle overrides the placeholder style of the Dialect.
+func WithPlaceholderStyle(style PlaceholderStyle) CompilerOption {
+	return func(c *Compiler) {
+		c.placeholderStyle = style
+	}
+}
+
+// WithPlaceholderReuse makes every occurrence of a placeholder
+// reuse the position of its first occurrence, e.g. $1 = $2 AND $2 <> $1.
+// The Dialect must support FeaturePlaceholderReuse.
+func WithPlaceholderReuse() CompilerOption {
+	return func(c *Compiler) {
+		c.placeholderReuse = true
+	}
+}
+
+// WithRequireWhere makes Compile fail with ErrMissingWhereClause
+// when an UPDATE or DELETE statement has no WHERE clause.
+func WithRequireWhere() CompilerOption {
+	return func(c *Compiler) {
+		c.requireWhere = true
+	}
+}
+
+// WithArrayBinding makes InSlice bind the whole slice to a single
+// array parameter, e.g. "id" = ANY($1), so that the SQL text does not
+// depend on the length of the slice. Dialects not supporting
+// FeatureQuantifiedArray keep expanding the slice into one placeholder
+// per element.
+//
+// Bind passes the slice to the driver as is. pgx accepts Go slices
+// as arrays, whereas lib/pq requires wrapping them with pq.Array
+// in the input of Bind, e.g. {"ids": pq.Array(ids)}.
+func WithArrayBinding() CompilerOption {
+	return func(c *Compiler) {
+		c.arrayBinding = true
+	}
+}
+
+// Compiler compiles a Node into a CompiledQuery.
+//
+// Compile does not modify the Compiler so
+// a single Compiler can be shared across goroutines.
 type Compiler struct {
-	dialect             Dialect
-	buffer              *bytes.Buffer
-	placeholderPosition uint
-	positionToName      map[uint]string
-	nameToPositions     map[string][]uint
+	compilerConfig
+
+	// The following fields are only used by
+	// the working copy created by Compile.
+	buffer          *bytes.Buffer
+	positionToName  []string
+	nameToPositions map[string][]uint
+	sliceLengths    map[string]int
+	// sliceLengthConflict is set when a slice name is
+	// registered with different lengths.
+	sliceLengthConflict bool
+	// outputOrderBy is set by a statement whose ORDER BY may only
+	// refer to output columns, right before transforming the clause.
+	outputOrderBy bool
+}
+
+// compilerConfig holds everything that affects the output of Compile.
+type compilerConfig struct {
+	dialect          Dialect
+	prettyPrint      bool
+	placeholderStyle PlaceholderStyle
+	placeholderReuse bool
+	requireWhere     bool
+	arrayBinding     bool
+}
+
+// NewCompiler returns a Compiler targeting the given Dialect.
+func NewCompiler(d Dialect, opts ...CompilerOption) *Compiler {
+	c := &Compiler{
+		compilerConfig: compilerConfig{
+			dialect: d,
+		},
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
 }
 
 func (c *Compiler) precedence(op OperatorType) uint {
@@ -20,8 +148,94 @@ func (c *Compiler) associativity(op OperatorType) Associativity {
 	return c.dialect.Associativity(op)
 }
 
-func (c *Compiler) makePlaceholder(name string, position uint) string {
-	return c.dialect.MakePlaceholder(name, position)
+func (c *Compiler) supports(f Feature) bool {
+	if s, ok := c.dialect.(FeatureSupporter); ok {
+		return s.Supports(f)
+	}
+	return true
+}
+
+// bindsArrays reports whether InSlice is bound as a single array.
+func (c *Compiler) bindsArrays() bool {
+	return c.arrayBinding && c.supports(FeatureQuantifiedArray)
+}
+
+// registerSlice records that the slice bound to name
+// is expanded into length placeholders.
+func (c *Compiler) registerSlice(name string, length int) {
+	if c.sliceLengths == nil {
+		c.sliceLengths = make(map[string]int)
+	}
+	if registered, ok := c.sliceLengths[name]; !ok {
+		c.sliceLengths[name] = length
+	} else if registered != length {
+		c.sliceLengthConflict = true
+	}
+}
+
+// sliceElementName returns the name of the placeholder
+// of the i-th element of the slice bound to name.
+func sliceElementName(name string, i int) string {
+	return name + "_" + strconv.Itoa(i+1)
+}
+
+func (c *Compiler) requireFeature(f Feature) error {
+	if !c.supports(f) {
+		return &UnsupportedError{
+			Dialect:   c.dialect,
+			Construct: f.String(),
+		}
+	}
+	return nil
+}
+
+func (c *Compiler) makePlaceholder(name string, position uint) (string, error) {
+	if c.placeholderStyle.named() && !placeholderNameRegexp.MatchString(name) {
+		return "", ErrIllegalPlaceholderName
+	}
+	switch c.placeholderStyle {
+	case PlaceholderQuestion:
+		return "?", nil
+	case PlaceholderDollar:
+		return fmt.Sprintf("$%d", position+1), nil
+	case PlaceholderColonName:
+		return ":" + name, nil
+	case PlaceholderAtName:
+		return "@" + name, nil
+	case PlaceholderDollarName:
+		return "$" + name, nil
+	}
+	return c.dialect.MakePlaceholder(name, position), nil
+}
+
+// deduplicatesPlaceholders reports whether every occurrence of
+// a placeholder shares the position of its first occurrence.
+func (c *Compiler) deduplicatesPlaceholders() bool {
+	return c.placeholderStyle.named() || c.placeholderReuse
+}
+
+// checkPlaceholderReuse verifies that positional placeholders
+// can be referred to more than once.
+func (c *Compiler) checkPlaceholderReuse() error {
+	if !c.placeholderReuse {
+		return nil
+	}
+	switch c.placeholderStyle {
+	case PlaceholderQuestion:
+		return &UnsupportedError{
+			Dialect:   c.dialect,
+			Construct: FeaturePlaceholderReuse.String() + " with ?",
+		}
+	case PlaceholderDialect:
+		if _, ok := c.dialect.(FeatureSupporter); !ok {
+			return &UnsupportedError{
+				Dialect:   c.dialect,
+				Construct: FeaturePlaceholderReuse.String(),
+			}
+		}
+		return c.requireFeature(FeaturePlaceholderReuse)
+	}
+	return nil
 }
 
 func (c *Compiler) WriteVerbatim(s string) {
@@ -35,14 +249,47 @@ func (c *Compiler) WriteIdentifier(i string) {
 	c.WriteVerbatim(c.dialect.QuoteIdentifier(i))
 }
 
-func (c *Compiler) insertPlaceholder(name string) uint {
-	pos := c.placeholderPosition
-	c.placeholderPosition += 1
+// WriteNode writes n. If the Dialect is a NodeChecker or a NodeRenderer,
+// it is given the chance to reject or render n first.
+// Nodes must write their children with WriteNode instead of
+// calling Stringify directly.
+func (c *Compiler) WriteNode(n Node) error {
+	if checker, ok := c.dialect.(NodeChecker); ok {
+		if err := checker.CheckNode(n); err != nil {
+			return err
+		}
+	}
+	if renderer, ok := c.dialect.(NodeRenderer); ok {
+		handled, err := renderer.RenderNode(c, n)
+		if err != nil {
+			return err
+		}
+		if handled {
+			return nil
+		}
+	}
+	return n.Stringify(c)
+}
 
-	if c.positionToName == nil {
-		c.positionToName = make(map[uint]string)
+// writeClauseSeparator writes the separator between two clauses
+// of a statement.
+func (c *Compiler) writeClauseSeparator() {
+	if c.prettyPrint {
+		c.WriteVerbatim("\n")
+	} else {
+		c.WriteVerbatim(" ")
+	}
+}
+
+func (c *Compiler) insertPlaceholder(name string) uint {
+	if c.deduplicatesPlaceholders() {
+		if positions, ok := c.nameToPositions[name]; ok {
+			return positions[0]
+		}
 	}
-	c.positionToName[pos] = name
+
+	pos := uint(len(c.positionToName))
+	c.positionToName = append(c.positionToName, name)
 
 	if c.nameToPositions == nil {
 		c.nameToPositions = make(map[string][]uint)
@@ -52,23 +299,108 @@ func (c *Compiler) insertPlaceholder(name string) uint {
 	return pos
 }
 
-func (c *Compiler) Compile(e Node) (string, error) {
-	c.buffer = &bytes.Buffer{}
-	c.placeholderPosition = 0
-	c.positionToName = make(map[uint]string)
-	c.nameToPositions = make(map[string][]uint)
-	if err := e.Transform(c).Stringify(c); err != nil {
-		return "", err
+// fork returns a copy of c with the same configuration
+// and fresh compilation state.
+func (c *Compiler) fork() *Compiler {
+	return &Compiler{
+		compilerConfig:  c.compilerConfig,
+		buffer:          &bytes.Buffer{},
+		nameToPositions: make(map[string][]uint),
+		sliceLengths:    make(map[string]int),
+	}
+}
+
+func (c *Compiler) Compile(e Node) (*CompiledQuery, error) {
+	w := c.fork()
+	if err := w.checkPlaceholderReuse(); err != nil {
+		return nil, err
+	}
+	transformed, _ := transformNode(e, w)
+	if w.sliceLengthConflict {
+		return nil, ErrSliceLengthConflict
+	}
+	if err := w.WriteNode(transformed); err != nil {
+		return nil, err
+	}
+	return &CompiledQuery{
+		sql:             w.buffer.String(),
+		positionToName:  w.positionToName,
+		nameToPositions: w.nameToPositions,
+		sliceLengths:    w.sliceLengths,
+		named:           w.placeholderStyle.named(),
+	}, nil
+}
+
+// CompiledQuery is the result of Compiler.Compile.
+//
+// CompiledQuery is immutable so it can be cached
+// and shared across goroutines.
+type CompiledQuery struct {
+	sql             string
+	positionToName  []string
+	nameToPositions map[string][]uint
+	sliceLengths    map[string]int
+	named           bool
+}
+
+// SQL returns the SQL text.
+func (q *CompiledQuery) SQL() string {
+	return q.sql
+}
+
+// Placeholders returns the placeholder names ordered by position.
+func (q *CompiledQuery) Placeholders() []string {
+	output := make([]string, len(q.positionToName))
+	copy(output, q.positionToName)
+	return output
+}
+
+// Positions returns a map from placeholder names to their positions.
+func (q *CompiledQuery) Positions() map[string][]uint {
+	output := make(map[string][]uint, len(q.nameToPositions))
+	for name, positions := range q.nameToPositions {
+		output[name] = append([]uint(nil), positions...)
+	}
+	return output
+}
+
+// spreadSlices replaces the slices bound to expanded InSlice
+// placeholders in input with their elements.
+func (q *CompiledQuery) spreadSlices(input map[string]interface{}) (map[string]interface{}, error) {
+	if len(q.sliceLengths) <= 0 {
+		return input, nil
+	}
+	output := make(map[string]interface{}, len(input))
+	for k, v := range input {
+		length, ok := q.sliceLengths[k]
+		if !ok {
+			output[k] = v
+			continue
+		}
+		value := reflect.ValueOf(v)
+		if value.Kind() != reflect.Slice && value.Kind() != reflect.Array || value.Len() != length {
+			return nil, ErrSliceLengthMismatch
+		}
+		for i := 0; i < length; i++ {
+			output[sliceElementName(k, i)] = value.Index(i).Interface()
+		}
 	}
-	return c.buffer.String(), nil
+	return output, nil
 }
 
-func (c *Compiler) BuildParams(input map[string]interface{}) ([]interface{}, error) {
+// Bind returns the arguments to be passed along with the SQL text.
+// A slice bound to an expanded InSlice must have the length
+// given to InSlice and is spread among its placeholders.
+func (q *CompiledQuery) Bind(input map[string]interface{}) ([]interface{}, error) {
+	input, err := q.spreadSlices(input)
+	if err != nil {
+		return nil, err
+	}
 	consumedLength := 0
-	output := make([]interface{}, c.placeholderPosition)
+	output := make([]interface{}, len(q.positionToName))
 
 	for k, v := range input {
-		positions, ok := c.nameToPositions[k]
+		positions, ok := q.nameToPositions[k]
 		if !ok {
 			return nil, ErrUnknownInputKey
 		}
@@ -78,9 +410,43 @@ func (c *Compiler) BuildParams(input map[string]interface{}) ([]interface{}, err
 		consumedLength += 1
 	}
 
-	if consumedLength != len(c.nameToPositions) {
+	if consumedLength != len(q.nameToPositions) {
 		return nil, ErrUnboundPlaceholder
 	}
 
 	return output, nil
 }
+
+// BindNamed is like Bind but returns one sql.NamedArg per
+// distinct placeholder, ordered by their first positions.
+// It returns ErrPositionalPlaceholders unless the query was compiled
+// with a named PlaceholderStyle, since positional placeholders such as
+// $1 or @p1 do not refer to the placeholder names.
+func (q *CompiledQuery) BindNamed(input map[string]interface{}) ([]sql.NamedArg, error) {
+	if !q.named {
+		return nil, ErrPositionalPlaceholders
+	}
+	input, err := q.spreadSlices(input)
+	if err != nil {
+		return nil, err
+	}
+	for k := range input {
+		if _, ok := q.nameToPositions[k]; !ok {
+			return nil, ErrUnknownInputKey
+		}
+	}
+
+	output := make([]sql.NamedArg, 0, len(q.nameToPositions))
+	for pos, name := range q.positionToName {
+		if q.nameToPositions[name][0] != uint(pos) {
+			continue
+		}
+		v, ok := input[name]
+		if !ok {
+			return nil, ErrUnboundPlaceholder
+		}
+		output = append(output, sql.Named(name, v))
+	}
+
+	return output, nil
+}
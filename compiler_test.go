@@ -1,6 +1,7 @@
 package flexsql
 
 import (
+	"database/sql"
 	"testing"
 )
 
@@ -26,18 +27,358 @@ func TestBuildParams(t *testing.T) {
 	}
 
 	for _, case_ := range cases {
-		c := &Compiler{
-			dialect: &Postgres{},
-		}
+		c := NewCompiler(&Postgres{})
 		out, err := c.Compile(case_.in)
 		if err != nil {
 			t.Errorf("unexpected error: %v", err)
+			continue
 		}
-		testEqual(t, out, case_.out)
-		params, err := c.BuildParams(case_.inputParams)
+		testEqual(t, out.SQL(), case_.out)
+		params, err := out.Bind(case_.inputParams)
 		if err != nil {
 			t.Errorf("unexpected error: %v", err)
 		}
 		testDeepEqual(t, params, case_.outputParams)
 	}
 }
+
+func TestCompilerOptions(t *testing.T) {
+	sel := &SelectStmt{
+		Columns: []*LabeledColumn{
+			&LabeledColumn{Placeholder("a"), "a"},
+		},
+		FromClause: &FromClause{&FromClauseItem{
+			TableRef: &LabeledTable{Name: "t", Label: "t"},
+		}},
+		WhereClause: &WhereClause{Eq(Placeholder("b"), Placeholder("c"))},
+	}
+
+	cases := []struct {
+		opts []CompilerOption
+		out  string
+	}{
+		{
+			nil,
+			`SELECT $1 "a" FROM "t" "t" WHERE $2 = $3`,
+		},
+		{
+			[]CompilerOption{WithPrettyPrint()},
+			"SELECT $1 \"a\"\nFROM \"t\" \"t\"\nWHERE $2 = $3",
+		},
+		{
+			[]CompilerOption{WithPlaceholderStyle(PlaceholderQuestion)},
+			`SELECT ? "a" FROM "t" "t" WHERE ? = ?`,
+		},
+	}
+
+	for _, case_ := range cases {
+		c := NewCompiler(&Postgres{}, case_.opts...)
+		out, err := c.Compile(sel)
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+			continue
+		}
+		testEqual(t, out.SQL(), case_.out)
+	}
+}
+
+func TestCompiledQuery(t *testing.T) {
+	c := NewCompiler(&Postgres{})
+
+	q1, err := c.Compile(Eq(Placeholder("a"), Placeholder("b")))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	q2, err := c.Compile(Add(Placeholder("b"), Placeholder("b")))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	testEqual(t, q1.SQL(), "$1 = $2")
+	testDeepEqual(t, q1.Placeholders(), []string{"a", "b"})
+	testDeepEqual(t, q1.Positions(), map[string][]uint{"a": {0}, "b": {1}})
+
+	testEqual(t, q2.SQL(), "$1 + $2")
+	testDeepEqual(t, q2.Placeholders(), []string{"b", "b"})
+	testDeepEqual(t, q2.Positions(), map[string][]uint{"b": {0, 1}})
+
+	params, err := q1.Bind(map[string]interface{}{"a": 1, "b": 2})
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	testDeepEqual(t, params, []interface{}{1, 2})
+
+	params, err = q2.Bind(map[string]interface{}{"b": 3})
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	testDeepEqual(t, params, []interface{}{3, 3})
+
+	_, err = q2.Bind(map[string]interface{}{"a": 1, "b": 3})
+	testEqual(t, err, ErrUnknownInputKey)
+	_, err = q1.Bind(map[string]interface{}{"a": 1})
+	testEqual(t, err, ErrUnboundPlaceholder)
+}
+
+// fetchFirstDialect is Postgres rendering LimitClause as FETCH FIRST
+// and rejecting OFFSET.
+type fetchFirstDialect struct {
+	Postgres
+}
+
+func (d *fetchFirstDialect) CheckNode(n Node) error {
+	if _, ok := n.(*OffsetClause); ok {
+		return &UnsupportedError{Dialect: d, Construct: "OFFSET"}
+	}
+	return nil
+}
+
+func (d *fetchFirstDialect) RenderNode(c *Compiler, n Node) (bool, error) {
+	l, ok := n.(*LimitClause)
+	if !ok {
+		return false, nil
+	}
+	c.WriteVerbatim("FETCH FIRST ")
+	if err := c.WriteNode(l.Expr); err != nil {
+		return true, err
+	}
+	c.WriteVerbatim(" ROWS ONLY")
+	return true, nil
+}
+
+func TestDialectHooks(t *testing.T) {
+	sel := &SelectStmt{
+		Columns: []*LabeledColumn{
+			&LabeledColumn{literal("1"), "a"},
+		},
+		LimitClause: &LimitClause{Placeholder("limit")},
+	}
+	testCompileDialect(t, &fetchFirstDialect{}, sel, `SELECT 1 "a" FETCH FIRST $1 ROWS ONLY`)
+
+	sel.OffsetClause = &OffsetClause{literal("1")}
+	testUnsupported(t, &fetchFirstDialect{}, sel, "OFFSET")
+}
+
+func TestNamedPlaceholders(t *testing.T) {
+	a := Placeholder("a")
+	b := Placeholder("b")
+	in := And(Eq(a, b), NotEq(b, a))
+	inputParams := map[string]interface{}{
+		"a": 1,
+		"b": 2,
+	}
+
+	cases := []struct {
+		style PlaceholderStyle
+		out   string
+	}{
+		{PlaceholderColonName, ":a = :b AND :b <> :a"},
+		{PlaceholderAtName, "@a = @b AND @b <> @a"},
+		{PlaceholderDollarName, "$a = $b AND $b <> $a"},
+	}
+
+	for _, case_ := range cases {
+		c := NewCompiler(&Postgres{}, WithPlaceholderStyle(case_.style))
+		out, err := c.Compile(in)
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+			continue
+		}
+		testEqual(t, out.SQL(), case_.out)
+		testDeepEqual(t, out.Placeholders(), []string{"a", "b"})
+
+		params, err := out.Bind(inputParams)
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+		testDeepEqual(t, params, []interface{}{1, 2})
+
+		namedParams, err := out.BindNamed(inputParams)
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+		testDeepEqual(t, namedParams, []sql.NamedArg{
+			sql.Named("a", 1),
+			sql.Named("b", 2),
+		})
+
+		_, err = out.BindNamed(map[string]interface{}{"a": 1})
+		testEqual(t, err, ErrUnboundPlaceholder)
+		_, err = out.BindNamed(map[string]interface{}{"a": 1, "b": 2, "c": 3})
+		testEqual(t, err, ErrUnknownInputKey)
+	}
+
+	c := NewCompiler(&SQLite{}, WithPlaceholderStyle(PlaceholderColonName))
+	_, err := c.Compile(Eq(Placeholder("a b"), a))
+	testEqual(t, err, ErrIllegalPlaceholderName)
+
+	out, err := NewCompiler(&Postgres{}).Compile(in)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	testEqual(t, out.SQL(), "$1 = $2 AND $3 <> $4")
+	_, err = out.BindNamed(inputParams)
+	testEqual(t, err, ErrPositionalPlaceholders)
+
+	for _, d := range []Dialect{&Postgres{}, &SQLServer{}} {
+		out, err = NewCompiler(d, WithPlaceholderReuse()).Compile(in)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		_, err = out.BindNamed(inputParams)
+		testEqual(t, err, ErrPositionalPlaceholders)
+	}
+}
+
+func TestPlaceholderReuse(t *testing.T) {
+	a := Placeholder("a")
+	b := Placeholder("b")
+	in := And(Eq(a, b), NotEq(b, a))
+
+	cases := []struct {
+		dialect Dialect
+		out     string
+	}{
+		{&Postgres{}, "$1 = $2 AND $2 <> $1"},
+		{&SQLite{}, "?1 = ?2 AND ?2 <> ?1"},
+		{&SQLServer{}, "@p1 = @p2 AND @p2 <> @p1"},
+	}
+
+	for _, case_ := range cases {
+		c := NewCompiler(case_.dialect, WithPlaceholderReuse())
+		out, err := c.Compile(in)
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+			continue
+		}
+		testEqual(t, out.SQL(), case_.out)
+		params, err := out.Bind(map[string]interface{}{
+			"a": 1,
+			"b": 2,
+		})
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+		testDeepEqual(t, params, []interface{}{1, 2})
+	}
+
+	c := NewCompiler(&MySQL{}, WithPlaceholderReuse())
+	_, err := c.Compile(in)
+	if _, ok := err.(*UnsupportedError); !ok {
+		t.Errorf("expected UnsupportedError but got: %v", err)
+	}
+
+	c = NewCompiler(&Postgres{}, WithPlaceholderReuse(), WithPlaceholderStyle(PlaceholderQuestion))
+	_, err = c.Compile(in)
+	if _, ok := err.(*UnsupportedError); !ok {
+		t.Errorf("expected UnsupportedError but got: %v", err)
+	}
+
+	// A Dialect has to opt in to placeholder reuse.
+	c = NewCompiler(&bareDialect{&Postgres{}}, WithPlaceholderReuse())
+	_, err = c.Compile(in)
+	if _, ok := err.(*UnsupportedError); !ok {
+		t.Errorf("expected UnsupportedError but got: %v", err)
+	}
+}
+
+// bareDialect implements none of the optional Dialect interfaces.
+type bareDialect struct {
+	Dialect
+}
+
+func TestArrayBinding(t *testing.T) {
+	id := &Column{"", "id"}
+	in := And(InSlice(id, "ids", 3), Eq(&Column{"", "a"}, Placeholder("a")))
+	input := map[string]interface{}{
+		"ids": []int{1, 2, 3},
+		"a":   4,
+	}
+
+	c := NewCompiler(&Postgres{}, WithArrayBinding())
+	out, err := c.Compile(in)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	testEqual(t, out.SQL(), `"id" = ANY($1) AND "a" = $2`)
+	params, err := out.Bind(input)
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	testDeepEqual(t, params, []interface{}{[]int{1, 2, 3}, 4})
+
+	// A slice wrapped for the driver, e.g. with pq.Array,
+	// is passed through unchanged.
+	wrapped := struct{ A []int }{[]int{1, 2, 3}}
+	params, err = out.Bind(map[string]interface{}{"ids": wrapped, "a": 4})
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	testDeepEqual(t, params, []interface{}{wrapped, 4})
+
+	out, err = c.Compile(Not(InSlice(id, "ids", 0)))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	testEqual(t, out.SQL(), `"id" <> ALL($1)`)
+
+	cases := []struct {
+		compiler *Compiler
+		out      string
+	}{
+		{NewCompiler(&Postgres{}), `"id" IN ($1,$2,$3) AND "a" = $4`},
+		{NewCompiler(&MySQL{}, WithArrayBinding()), "`id` IN (?,?,?) AND `a` = ?"},
+		{NewCompiler(&SQLite{}, WithArrayBinding()), `"id" IN (?1,?2,?3) AND "a" = ?4`},
+		{NewCompiler(&SQLServer{}, WithArrayBinding()), `[id] IN (@p1,@p2,@p3) AND [a] = @p4`},
+	}
+	for _, case_ := range cases {
+		out, err := case_.compiler.Compile(in)
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+			continue
+		}
+		testEqual(t, out.SQL(), case_.out)
+		testDeepEqual(t, out.Placeholders(), []string{"ids_1", "ids_2", "ids_3", "a"})
+		params, err := out.Bind(input)
+		if err != nil {
+			t.Errorf("unexpected error: %v", err)
+		}
+		testDeepEqual(t, params, []interface{}{1, 2, 3, 4})
+
+		_, err = out.Bind(map[string]interface{}{"ids": []int{1, 2}, "a": 4})
+		testEqual(t, err, ErrSliceLengthMismatch)
+		_, err = out.Bind(map[string]interface{}{"ids": 1, "a": 4})
+		testEqual(t, err, ErrSliceLengthMismatch)
+	}
+
+	c = NewCompiler(&MySQL{}, WithPlaceholderStyle(PlaceholderColonName))
+	out, err = c.Compile(Or(NotInSlice(id, "ids", 2), InSlice(id, "ids", 2)))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	testEqual(t, out.SQL(), "`id` NOT IN (:ids_1,:ids_2) OR `id` IN (:ids_1,:ids_2)")
+	namedParams, err := out.BindNamed(map[string]interface{}{"ids": [2]string{"x", "y"}})
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	testDeepEqual(t, namedParams, []sql.NamedArg{
+		sql.Named("ids_1", "x"),
+		sql.Named("ids_2", "y"),
+	})
+
+	out, err = NewCompiler(&MySQL{}).Compile(InSlice(id, "ids", 0))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	testEqual(t, out.SQL(), "FALSE")
+	params, err = out.Bind(map[string]interface{}{"ids": []int{}})
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	testEqual(t, len(params), 0)
+
+	_, err = NewCompiler(&Postgres{}).Compile(Or(InSlice(id, "ids", 2), InSlice(id, "ids", 3)))
+	testEqual(t, err, ErrSliceLengthConflict)
+	_, err = NewCompiler(&Postgres{}, WithArrayBinding()).Compile(Or(InSlice(id, "ids", 2), InSlice(id, "ids", 3)))
+	testEqual(t, err, nil)
+}
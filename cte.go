@@ -0,0 +1,162 @@
+package flexsql
+
+import (
+	"errors"
+)
+
+var ErrInvalidCTEQuery = errors.New("Common table expression requires either SelectStmt or SetOperation")
+
+// Materialization controls whether a common table expression
+// is computed once or inlined into the referring query.
+type Materialization int
+
+const (
+	// MaterializedDefault leaves the choice to the database.
+	MaterializedDefault Materialization = iota
+	// Materialized is AS MATERIALIZED.
+	Materialized
+	// NotMaterialized is AS NOT MATERIALIZED.
+	NotMaterialized
+)
+
+// CommonTableExpr is a named query of a WITH clause, given by either
+// SelectStmt or SetOperation.
+// Refer to it with a LabeledTable of the same Name.
+// Columns may be empty to use the column names of the query.
+type CommonTableExpr struct {
+	Name            string
+	Columns         []string
+	Materialization Materialization
+	SelectStmt      *SelectStmt
+	SetOperation    *SetOperation
+}
+
+func (cte *CommonTableExpr) Transform(c *Compiler) Node {
+	copied := *cte
+	changed := false
+
+	if cte.SelectStmt != nil {
+		if n, ok := transformNode(cte.SelectStmt, c); ok {
+			copied.SelectStmt = n.(*SelectStmt)
+			changed = true
+		}
+	}
+	if cte.SetOperation != nil {
+		if n, ok := transformNode(cte.SetOperation, c); ok {
+			copied.SetOperation = n.(*SetOperation)
+			changed = true
+		}
+	}
+
+	if !changed {
+		return cte
+	}
+	return &copied
+}
+
+func (cte *CommonTableExpr) Stringify(c *Compiler) error {
+	var query Node
+	switch {
+	case cte.SelectStmt != nil && cte.SetOperation == nil:
+		query = cte.SelectStmt
+	case cte.SetOperation != nil && cte.SelectStmt == nil:
+		query = cte.SetOperation
+	default:
+		return ErrInvalidCTEQuery
+	}
+
+	c.WriteIdentifier(cte.Name)
+	if len(cte.Columns) > 0 {
+		c.WriteVerbatim(" ")
+		stringifyIdentifierList(cte.Columns, c)
+	}
+	c.WriteVerbatim(" AS ")
+	switch cte.Materialization {
+	case Materialized:
+		if err := c.requireFeature(FeatureCTEMaterialization); err != nil {
+			return err
+		}
+		c.WriteVerbatim("MATERIALIZED ")
+	case NotMaterialized:
+		if err := c.requireFeature(FeatureCTEMaterialization); err != nil {
+			return err
+		}
+		c.WriteVerbatim("NOT MATERIALIZED ")
+	}
+	return stringifyParen(query, c)
+}
+
+// WithClause prefixes SELECT, INSERT, UPDATE and DELETE statements
+// with common table expressions.
+type WithClause struct {
+	Recursive bool
+	CTEs      []*CommonTableExpr
+}
+
+func With(first *CommonTableExpr, rest ...*CommonTableExpr) *WithClause {
+	ctes := make([]*CommonTableExpr, 1+len(rest))
+	ctes[0] = first
+	for i, v := range rest {
+		ctes[i+1] = v
+	}
+	return &WithClause{CTEs: ctes}
+}
+
+func WithRecursive(first *CommonTableExpr, rest ...*CommonTableExpr) *WithClause {
+	w := With(first, rest...)
+	w.Recursive = true
+	return w
+}
+
+func (w *WithClause) Transform(c *Compiler) Node {
+	var ctes []*CommonTableExpr
+	for i, v := range w.CTEs {
+		t, cteChanged := transformNode(v, c)
+		if cteChanged && ctes == nil {
+			ctes = make([]*CommonTableExpr, len(w.CTEs))
+			copy(ctes, w.CTEs)
+		}
+		if ctes != nil {
+			ctes[i] = t.(*CommonTableExpr)
+		}
+	}
+	if ctes == nil {
+		return w
+	}
+	return &WithClause{
+		Recursive: w.Recursive,
+		CTEs:      ctes,
+	}
+}
+
+func (w *WithClause) Stringify(c *Compiler) error {
+	if len(w.CTEs) <= 0 {
+		return ErrZeroLength
+	}
+	c.WriteVerbatim("WITH ")
+	if w.Recursive {
+		c.WriteVerbatim("RECURSIVE ")
+	}
+	for i, cte := range w.CTEs {
+		if i > 0 {
+			c.WriteVerbatim(",")
+		}
+		if err := c.WriteNode(cte); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// stringifyWithClause writes w followed by a clause separator
+// if w is not nil.
+func stringifyWithClause(w *WithClause, c *Compiler) error {
+	if w == nil {
+		return nil
+	}
+	if err := c.WriteNode(w); err != nil {
+		return err
+	}
+	c.writeClauseSeparator()
+	return nil
+}
@@ -0,0 +1,113 @@
+package flexsql
+
+import (
+	"testing"
+)
+
+func TestWithClause(t *testing.T) {
+	a := Placeholder("a")
+	recent := &CommonTableExpr{
+		Name: "recent",
+		SelectStmt: &SelectStmt{
+			Columns:     []*LabeledColumn{{&Column{"p", "id"}, "id"}},
+			FromClause:  &FromClause{&FromClauseItem{TableRef: &LabeledTable{"", "post", "p"}}},
+			WhereClause: &WhereClause{Gt(&Column{"p", "created_at"}, a)},
+		},
+	}
+	fromRecent := &FromClause{&FromClauseItem{TableRef: &LabeledTable{"", "recent", "r"}}}
+	columns := []*LabeledColumn{{&Column{"r", "id"}, "Post_ID"}}
+
+	cases := []compileTest{
+		{
+			&SelectStmt{
+				WithClause: With(recent),
+				Columns:    columns,
+				FromClause: fromRecent,
+			},
+			`WITH "recent" AS (SELECT "p"."id" "id" FROM "post" "p" WHERE "p"."created_at" > $1) SELECT "r"."id" "Post_ID" FROM "recent" "r"`,
+		},
+		{
+			&SelectStmt{
+				WithClause: With(
+					&CommonTableExpr{
+						Name:            "recent",
+						Columns:         []string{"x"},
+						Materialization: NotMaterialized,
+						SelectStmt:      recent.SelectStmt,
+					},
+					&CommonTableExpr{
+						Name:            "other",
+						Materialization: Materialized,
+						SelectStmt:      recent.SelectStmt,
+					},
+				),
+				Columns:    columns,
+				FromClause: fromRecent,
+			},
+			`WITH "recent" ("x") AS NOT MATERIALIZED (SELECT "p"."id" "id" FROM "post" "p" WHERE "p"."created_at" > $1),` +
+				`"other" AS MATERIALIZED (SELECT "p"."id" "id" FROM "post" "p" WHERE "p"."created_at" > $2) SELECT "r"."id" "Post_ID" FROM "recent" "r"`,
+		},
+		{
+			&DeleteStmt{
+				WithClause:  With(recent),
+				Table:       &LabeledTable{"", "post", "p"},
+				UsingClause: &UsingClause{&FromClauseItem{TableRef: &LabeledTable{"", "recent", "r"}}},
+				WhereClause: &WhereClause{Eq(&Column{"p", "id"}, &Column{"r", "id"})},
+			},
+			`WITH "recent" AS (SELECT "p"."id" "id" FROM "post" "p" WHERE "p"."created_at" > $1) DELETE FROM "post" "p" USING "recent" "r" WHERE "p"."id" = "r"."id"`,
+		},
+		{
+			&UpdateStmt{
+				WithClause:  With(recent),
+				Table:       &LabeledTable{"", "post", "p"},
+				Assignments: []*Assignment{Set("hidden", a)},
+				FromClause:  fromRecent,
+				WhereClause: &WhereClause{Eq(&Column{"p", "id"}, &Column{"r", "id"})},
+			},
+			`WITH "recent" AS (SELECT "p"."id" "id" FROM "post" "p" WHERE "p"."created_at" > $1) UPDATE "post" "p" SET "hidden" = $2 FROM "recent" "r" WHERE "p"."id" = "r"."id"`,
+		},
+		{
+			&InsertStmt{
+				WithClause: With(recent),
+				Table:      &Table{"", "archive"},
+				SelectStmt: &SelectStmt{Columns: columns, FromClause: fromRecent},
+			},
+			`WITH "recent" AS (SELECT "p"."id" "id" FROM "post" "p" WHERE "p"."created_at" > $1) INSERT INTO "archive" SELECT "r"."id" "Post_ID" FROM "recent" "r"`,
+		},
+	}
+	testMany(t, cases)
+
+	_, err := NewCompiler(&Postgres{}).Compile(&SelectStmt{WithClause: &WithClause{}, Columns: columns})
+	testEqual(t, err, ErrZeroLength)
+
+	_, err = NewCompiler(&Postgres{}).Compile(&SelectStmt{WithClause: With(&CommonTableExpr{Name: "t"}), Columns: columns})
+	testEqual(t, err, ErrInvalidCTEQuery)
+}
+
+func TestWithRecursive(t *testing.T) {
+	n := &Column{"", "n"}
+	limit := Placeholder("limit")
+	sel := &SelectStmt{
+		WithClause: WithRecursive(&CommonTableExpr{
+			Name:    "t",
+			Columns: []string{"n"},
+			SelectStmt: &SelectStmt{
+				Columns:     []*LabeledColumn{{Add(n, Placeholder("step")), "n"}},
+				FromClause:  &FromClause{&FromClauseItem{TableRef: &LabeledTable{"", "t", "t"}}},
+				WhereClause: &WhereClause{Lt(n, limit)},
+			},
+		}),
+		Columns:    []*LabeledColumn{{n, "T_N"}},
+		FromClause: &FromClause{&FromClauseItem{TableRef: &LabeledTable{"", "t", "t"}}},
+	}
+
+	testCompile(t, sel, `WITH RECURSIVE "t" ("n") AS (SELECT "n" + $1 "n" FROM "t" "t" WHERE "n" < $2) SELECT "n" "T_N" FROM "t" "t"`)
+	testCompileDialect(t, &MySQL{}, sel, "WITH RECURSIVE `t` (`n`) AS (SELECT `n` + ? `n` FROM `t` `t` WHERE `n` < ?) SELECT `n` `T_N` FROM `t` `t`")
+	testCompileDialect(t, &SQLServer{}, sel, `WITH [t] ([n]) AS (SELECT [n] + @p1 [n] FROM [t] [t] WHERE [n] < @p2) SELECT [n] [T_N] FROM [t] [t]`)
+	testDeepEqual(t, sel.WithClause.Recursive, true)
+
+	sel.WithClause.CTEs[0].Materialization = Materialized
+	testCompileDialect(t, &SQLite{}, sel, `WITH RECURSIVE "t" ("n") AS MATERIALIZED (SELECT "n" + ?1 "n" FROM "t" AS "t" WHERE "n" < ?2) SELECT "n" "T_N" FROM "t" AS "t"`)
+	testUnsupported(t, &MySQL{}, sel, "MATERIALIZED")
+	testUnsupported(t, &SQLServer{}, sel, "MATERIALIZED")
+}
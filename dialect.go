@@ -1,8 +1,149 @@
 package flexsql
 
+import (
+	"fmt"
+)
+
 type Dialect interface {
 	QuoteIdentifier(i string) string
 	MakePlaceholder(name string, position uint) string
 	Precedence(op OperatorType) uint
 	Associativity(op OperatorType) Associativity
 }
+
+// Feature is a construct that not every Dialect supports.
+type Feature uint
+
+const (
+	_ Feature = iota
+	// FeatureILike is the ILIKE and NOT ILIKE operators.
+	FeatureILike
+	// FeatureNullsOrdering is NULLS FIRST and NULLS LAST in ORDER BY.
+	FeatureNullsOrdering
+	// FeaturePlaceholderReuse is referring to a positional placeholder
+	// more than once, e.g. $1 in Postgres. Unlike other features,
+	// a Dialect must opt in to it through FeatureSupporter.
+	FeaturePlaceholderReuse
+	// FeatureCTEMaterialization is MATERIALIZED and NOT MATERIALIZED
+	// in a common table expression.
+	FeatureCTEMaterialization
+	// FeatureDistinctOn is SELECT DISTINCT ON.
+	FeatureDistinctOn
+	// FeatureAggregateFilter is FILTER (WHERE ...) of aggregate functions.
+	FeatureAggregateFilter
+	// FeatureGroupsFrame is GROUPS frames of window functions.
+	FeatureGroupsFrame
+	// FeatureQuantifiedSubquery is ANY and ALL with a subquery.
+	FeatureQuantifiedSubquery
+	// FeatureQuantifiedArray is ANY and ALL with an array.
+	FeatureQuantifiedArray
+	// FeatureConcat is the || string concatenation operator.
+	FeatureConcat
+	// FeatureIs is the IS and IS NOT operators comparing
+	// two arbitrary expressions, e.g. in SQLite.
+	FeatureIs
+	// FeatureGlob is the GLOB and NOT GLOB operators of SQLite.
+	FeatureGlob
+)
+
+func (f Feature) String() string {
+	switch f {
+	case FeatureILike:
+		return "ILIKE"
+	case FeatureNullsOrdering:
+		return "NULLS FIRST/LAST"
+	case FeaturePlaceholderReuse:
+		return "placeholder reuse"
+	case FeatureCTEMaterialization:
+		return "MATERIALIZED"
+	case FeatureDistinctOn:
+		return "DISTINCT ON"
+	case FeatureAggregateFilter:
+		return "FILTER"
+	case FeatureGroupsFrame:
+		return "GROUPS"
+	case FeatureQuantifiedSubquery:
+		return "ANY/ALL"
+	case FeatureQuantifiedArray:
+		return "ANY/ALL with array"
+	case FeatureConcat:
+		return "||"
+	case FeatureIs:
+		return "IS"
+	case FeatureGlob:
+		return "GLOB"
+	}
+	return fmt.Sprintf("Feature(%d)", uint(f))
+}
+
+// FeatureSupporter is implemented by a Dialect
+// that does not support every Feature.
+// A Dialect not implementing it is assumed to support all features
+// except FeaturePlaceholderReuse.
+type FeatureSupporter interface {
+	Supports(f Feature) bool
+}
+
+// NodeChecker is implemented by a Dialect that cannot express
+// some nodes. CheckNode is called before every node is written and
+// should return an *UnsupportedError for the nodes it rejects.
+type NodeChecker interface {
+	CheckNode(n Node) error
+}
+
+// NodeRenderer is implemented by a Dialect that renders some nodes
+// differently. RenderNode is called before every node is written and
+// reports whether it has rendered n. When it has not,
+// n is rendered as usual.
+type NodeRenderer interface {
+	RenderNode(c *Compiler, n Node) (bool, error)
+}
+
+// NullsOrderingEmulator is implemented by a Dialect not supporting
+// FeatureNullsOrdering to have NULLS FIRST and NULLS LAST emulated.
+// When the requested order of NULLs differs from the default order,
+// the Compiler sorts by NullsSortKey of the expression first, unless
+// ORDER BY may only refer to output columns as with DISTINCT and
+// set operations, in which case an UnsupportedError is returned.
+type NullsOrderingEmulator interface {
+	// NullsFirstByDefault reports whether NULLs sort
+	// before other values in ascending order.
+	NullsFirstByDefault() bool
+	// NullsSortKey returns an expression that is greater
+	// when expr is NULL than when it is not.
+	NullsSortKey(expr Expr) Expr
+}
+
+// UnsupportedError is returned by Compile when
+// the Node uses a construct the Dialect cannot express.
+type UnsupportedError struct {
+	Dialect   Dialect
+	Construct string
+}
+
+func (e *UnsupportedError) Error() string {
+	return fmt.Sprintf("%T does not support %v", e.Dialect, e.Construct)
+}
+
+// Rewriter is implemented by a Dialect that emulates
+// constructs it lacks with ones it supports.
+// Rewrite is called with every node after the node has been transformed
+// and returns the node to be used instead.
+// Like Transform, Rewrite must not modify n.
+type Rewriter interface {
+	Rewrite(n Node) Node
+}
+
+// RowLimitRenderer is implemented by a Dialect with its own syntax
+// for limiting rows. It renders LimitClause and OffsetClause in place of
+// the default LIMIT and OFFSET. At least one of limit and offset is non-nil.
+// orderBy is the ORDER BY clause of the same statement, if any.
+type RowLimitRenderer interface {
+	RenderRowLimit(c *Compiler, orderBy *OrderByClause, limit *LimitClause, offset *OffsetClause) error
+}
+
+// ParameterLimiter is implemented by a Dialect that limits
+// the number of bind parameters of a single statement.
+type ParameterLimiter interface {
+	MaxParameters() int
+}
@@ -0,0 +1,167 @@
+package flexsql
+
+import (
+	"strings"
+)
+
+type MySQL struct{}
+
+func (m *MySQL) QuoteIdentifier(i string) string {
+	return "`" + strings.Replace(i, "`", "``", -1) + "`"
+}
+
+func (m *MySQL) MakePlaceholder(name string, position uint) string {
+	return "?"
+}
+
+func (m *MySQL) Precedence(op OperatorType) uint {
+	switch op {
+	case OpOr:
+		return 1
+	case OpAnd:
+		return 2
+	case OpNot, OpNotExists:
+		return 3
+	case OpBetween, OpNotBetween:
+		return 4
+	case OpLt, OpGt, OpEq, OpLte, OpGte, OpNotEq, OpIsNull, OpIsNotNull, OpIsTrue, OpIsNotTrue, OpIsFalse, OpIsNotFalse, OpIn, OpNotIn, OpLike, OpNotLike:
+		return 5
+	case OpAdd, OpSub:
+		return 7
+	case OpMul, OpDiv, OpMod:
+		return 8
+	case OpExists:
+		return 9
+	}
+	return 0
+}
+
+func (m *MySQL) Associativity(op OperatorType) Associativity {
+	switch op {
+	case OpIsNull, OpIsNotNull, OpIsTrue, OpIsNotTrue, OpIsFalse, OpIsNotFalse, OpOr, OpAnd, OpAdd, OpSub, OpMul, OpDiv, OpMod, OpLt, OpGt, OpEq, OpLte, OpGte, OpNotEq, OpIn, OpNotIn, OpLike, OpNotLike:
+		return LeftAssociative
+	case OpNot, OpExists, OpNotExists:
+		return RightAssociative
+	case OpBetween, OpNotBetween:
+		return NonAssociative
+	}
+	return 0
+}
+
+func (m *MySQL) Supports(f Feature) bool {
+	switch f {
+	case FeatureILike, FeatureNullsOrdering, FeaturePlaceholderReuse,
+		FeatureCTEMaterialization, FeatureDistinctOn, FeatureAggregateFilter, FeatureGroupsFrame,
+		FeatureQuantifiedArray, FeatureConcat, FeatureIs, FeatureGlob:
+		return false
+	}
+	return true
+}
+
+// CheckNode rejects FULL JOIN, UPDATE ... FROM, DELETE ... USING
+// and RETURNING, which MySQL lacks.
+func (m *MySQL) CheckNode(n Node) error {
+	construct := ""
+	switch v := n.(type) {
+	case *JoinClause:
+		if v.JoinType() == "FULL JOIN" {
+			construct = "FULL JOIN"
+		}
+	case *UpdateStmt:
+		if v.FromClause != nil {
+			construct = "UPDATE ... FROM"
+		}
+	case *UsingClause:
+		construct = "DELETE ... USING"
+	case *ReturningClause:
+		construct = "RETURNING"
+	}
+	if construct != "" {
+		return &UnsupportedError{
+			Dialect:   m,
+			Construct: construct,
+		}
+	}
+	return nil
+}
+
+// RenderNode renders SQLType with the names accepted by CAST in MySQL,
+// OnConflictClause as ON DUPLICATE KEY UPDATE and StringLiteral with
+// backslash escapes, which assumes NO_BACKSLASH_ESCAPES is not set.
+func (m *MySQL) RenderNode(c *Compiler, n Node) (bool, error) {
+	switch v := n.(type) {
+	case StringLiteral:
+		c.WriteVerbatim(quoteString(mysqlStringEscaper.Replace(string(v))))
+		return true, nil
+	case SQLType:
+		return m.renderSQLType(c, v)
+	case *OnConflictClause:
+		return true, m.renderOnConflict(c, v)
+	case *ExcludedColumn:
+		c.WriteVerbatim("VALUES(")
+		c.WriteIdentifier(v.Name)
+		c.WriteVerbatim(")")
+		return true, nil
+	}
+	return false, nil
+}
+
+// renderOnConflict renders ON DUPLICATE KEY UPDATE. MySQL does not take
+// a conflict target, so DO NOTHING is emulated by assigning
+// the first target column to itself.
+func (m *MySQL) renderOnConflict(c *Compiler, o *OnConflictClause) error {
+	if o.TargetWhereClause != nil || o.WhereClause != nil {
+		return &UnsupportedError{
+			Dialect:   m,
+			Construct: "ON CONFLICT ... WHERE",
+		}
+	}
+	assignments := o.Assignments
+	if o.IsDoNothing() {
+		if len(o.Columns) <= 0 {
+			return &UnsupportedError{
+				Dialect:   m,
+				Construct: "ON CONFLICT DO NOTHING without conflict target",
+			}
+		}
+		assignments = []*Assignment{Set(o.Columns[0], &Column{Name: o.Columns[0]})}
+	}
+	c.WriteVerbatim("ON DUPLICATE KEY UPDATE ")
+	return stringifyAssignments(assignments, c)
+}
+
+var mysqlStringEscaper = strings.NewReplacer(`\`, `\\`, "\x00", `\0`)
+
+func (m *MySQL) renderSQLType(c *Compiler, sqlType SQLType) (bool, error) {
+	switch sqlType {
+	case Smallint, Integer, Bigint:
+		c.WriteVerbatim("SIGNED")
+	case Boolean:
+		return true, &UnsupportedError{
+			Dialect:   m,
+			Construct: "CAST AS BOOLEAN",
+		}
+	case Real, DoublePrecision:
+		c.WriteVerbatim("DOUBLE")
+	case Text:
+		c.WriteVerbatim("CHAR")
+	case Timestamp:
+		c.WriteVerbatim("DATETIME")
+	default:
+		return false, nil
+	}
+	return true, nil
+}
+
+func (m *MySQL) NullsFirstByDefault() bool {
+	return true
+}
+
+// NullsSortKey returns CASE WHEN expr IS NULL THEN 1 ELSE 0 END.
+func (m *MySQL) NullsSortKey(expr Expr) Expr {
+	return caseNullsSortKey(expr)
+}
+
+func (m *MySQL) MaxParameters() int {
+	return 65535
+}
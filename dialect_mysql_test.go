@@ -0,0 +1,92 @@
+package flexsql
+
+import (
+	"testing"
+)
+
+func TestMySQLQuoteIdentifier(t *testing.T) {
+	m := MySQL{}
+	cases := [][]string{
+		{"a", "`a`"},
+		{"1a", "`1a`"},
+		{"a`b", "`a``b`"},
+		{"日本語", "`日本語`"},
+	}
+	for _, case_ := range cases {
+		input := case_[0]
+		expected := case_[1]
+		actual := m.QuoteIdentifier(input)
+		testEqual(t, actual, expected)
+	}
+}
+
+func TestMySQLMakePlaceholder(t *testing.T) {
+	m := MySQL{}
+	testEqual(t, m.MakePlaceholder("unimportant", 0), "?")
+	testEqual(t, m.MakePlaceholder("unimportant", 1), "?")
+}
+
+func TestMySQLOperator(t *testing.T) {
+	f := literal("f")
+	cases := []compileTest{
+		{Eq(Eq(f, f), f), "f = f = f"},
+		{Eq(f, Eq(f, f)), "f = (f = f)"},
+		{IsNull(Eq(f, f)), "f = f IS NULL"},
+		{Eq(f, IsNull(f)), "f = (f IS NULL)"},
+		{Eq(Between(f, f, f), f), "(f BETWEEN f AND f) = f"},
+		{Between(Eq(f, f), f, f), "f = f BETWEEN f AND f"},
+		{Not(Between(f, f, f)), "f NOT BETWEEN f AND f"},
+		{And(Not(f), Or(f, f)), "NOT f AND (f OR f)"},
+		{Sub(f, Add(f, f)), "f - (f + f)"},
+		{Eq(Placeholder("a"), Placeholder("a")), "? = ?"},
+	}
+	for _, case_ := range cases {
+		testCompileDialect(t, &MySQL{}, case_.in, case_.out)
+	}
+}
+
+func TestMySQLUnsupported(t *testing.T) {
+	f := literal("f")
+	testUnsupported(t, &MySQL{}, ILike(f, f), "ILIKE")
+	testUnsupported(t, &MySQL{}, Not(ILike(f, f)), "ILIKE")
+	testUnsupported(t, &MySQL{}, Concat(f, f), "||")
+	testUnsupported(t, &MySQL{}, Not(Is(f, f)), "IS")
+	testUnsupported(t, &MySQL{}, Glob(f, f), "GLOB")
+	testCompileDialect(t, &MySQL{}, OrderBy(Desc(&Column{"t", "a"})), "ORDER BY `t`.`a` DESC")
+}
+
+func TestMySQLRenderNode(t *testing.T) {
+	a := literal("a")
+	cases := []compileTest{
+		{Cast(a, Integer), "CAST(a AS SIGNED)"},
+		{Cast(a, Text), "CAST(a AS CHAR)"},
+		{Cast(a, Timestamp), "CAST(a AS DATETIME)"},
+		{Cast(a, Decimal(3, 4)), "CAST(a AS DECIMAL(3,4))"},
+	}
+	for _, case_ := range cases {
+		testCompileDialect(t, &MySQL{}, case_.in, case_.out)
+	}
+	testUnsupported(t, &MySQL{}, Cast(a, Boolean), "CAST AS BOOLEAN")
+}
+
+func TestMySQLCheckNode(t *testing.T) {
+	left := &FromClauseItem{TableRef: &LabeledTable{Name: "a", Label: "a"}}
+	right := &FromClauseItem{TableRef: &LabeledTable{Name: "b", Label: "b"}}
+	on := literal("TRUE")
+	testCompileDialect(t, &MySQL{}, LeftJoin(left, right, on), "`a` `a` LEFT JOIN `b` `b` ON TRUE")
+	testUnsupported(t, &MySQL{}, FullJoin(left, right, on), "FULL JOIN")
+	testUnsupported(t, &MySQL{}, &FromClause{&FromClauseItem{JoinClause: FullJoin(left, right, on)}}, "FULL JOIN")
+}
+
+func TestMySQLNullsOrdering(t *testing.T) {
+	f := &Column{"", "f"}
+	cases := []compileTest{
+		{OrderBy(NullsFirst(Asc(f))), "ORDER BY `f`"},
+		{OrderBy(NullsLast(Desc(f))), "ORDER BY `f` DESC"},
+		{OrderBy(NullsLast(Asc(f))), "ORDER BY CASE WHEN `f` IS NULL THEN 1 ELSE 0 END,`f`"},
+		{OrderBy(NullsFirst(Desc(f))), "ORDER BY CASE WHEN `f` IS NULL THEN 1 ELSE 0 END DESC,`f` DESC"},
+	}
+	for _, case_ := range cases {
+		testCompileDialect(t, &MySQL{}, case_.in, case_.out)
+	}
+}
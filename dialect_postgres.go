@@ -2,7 +2,9 @@ package flexsql
 
 import (
 	"bytes"
+	"encoding/hex"
 	"fmt"
+	"strings"
 )
 
 type Postgres struct{}
@@ -45,6 +47,42 @@ func (p *Postgres) QuoteIdentifier(i string) string {
 	return "U&" + s
 }
 
+// RenderNode renders StringLiteral containing backslashes as
+// an escape string, which is not affected by standard_conforming_strings,
+// and BytesLiteral as bytea in hex format.
+func (p *Postgres) RenderNode(c *Compiler, n Node) (bool, error) {
+	switch v := n.(type) {
+	case StringLiteral:
+		s := string(v)
+		if strings.ContainsRune(s, 0) {
+			return true, &UnsupportedError{
+				Dialect:   p,
+				Construct: "NUL in string literal",
+			}
+		}
+		if !strings.ContainsRune(s, '\\') {
+			return false, nil
+		}
+		c.WriteVerbatim("E")
+		c.WriteVerbatim(quoteString(strings.Replace(s, `\`, `\\`, -1)))
+		return true, nil
+	case *BytesLiteral:
+		c.WriteVerbatim(`E'\\x`)
+		c.WriteVerbatim(hex.EncodeToString(v.Bytes))
+		c.WriteVerbatim(`'::bytea`)
+		return true, nil
+	}
+	return false, nil
+}
+
+func (p *Postgres) Supports(f Feature) bool {
+	switch f {
+	case FeatureIs, FeatureGlob:
+		return false
+	}
+	return true
+}
+
 func (p *Postgres) MakePlaceholder(name string, position uint) string {
 	return fmt.Sprintf("$%d", position+1)
 }
@@ -55,7 +93,7 @@ func (p *Postgres) Precedence(op OperatorType) uint {
 		return 1
 	case OpAnd:
 		return 2
-	case OpNot:
+	case OpNot, OpNotExists:
 		return 3
 	case OpIsNull, OpIsNotNull, OpIsTrue, OpIsNotTrue, OpIsFalse, OpIsNotFalse:
 		return 4
@@ -63,22 +101,30 @@ func (p *Postgres) Precedence(op OperatorType) uint {
 		return 5
 	case OpBetween, OpNotBetween, OpIn, OpNotIn, OpLike, OpNotLike, OpILike, OpNotILike:
 		return 6
+	case OpConcat:
+		return 7
 	case OpAdd, OpSub:
 		return 8
 	case OpMul, OpDiv, OpMod:
 		return 9
+	case OpExists:
+		return 10
 	}
 	return 0
 }
 
 func (p *Postgres) Associativity(op OperatorType) Associativity {
 	switch op {
-	case OpIsNull, OpIsNotNull, OpIsTrue, OpIsNotTrue, OpIsFalse, OpIsNotFalse, OpOr, OpAnd, OpAdd, OpSub, OpMul, OpDiv, OpMod:
+	case OpIsNull, OpIsNotNull, OpIsTrue, OpIsNotTrue, OpIsFalse, OpIsNotFalse, OpOr, OpAnd, OpConcat, OpAdd, OpSub, OpMul, OpDiv, OpMod:
 		return LeftAssociative
-	case OpNot:
+	case OpNot, OpExists, OpNotExists:
 		return RightAssociative
 	case OpLt, OpGt, OpEq, OpLte, OpGte, OpNotEq, OpBetween, OpNotBetween, OpIn, OpNotIn, OpLike, OpNotLike, OpILike, OpNotILike:
 		return NonAssociative
 	}
 	return 0
 }
+
+func (p *Postgres) MaxParameters() int {
+	return 65535
+}
@@ -23,3 +23,10 @@ func TestMakePlaceholder(t *testing.T) {
 	p := Postgres{}
 	testEqual(t, p.MakePlaceholder("unimportant", 0), "$1")
 }
+
+func TestPostgresUnsupported(t *testing.T) {
+	f := literal("f")
+	testUnsupported(t, &Postgres{}, Is(f, f), "IS")
+	testUnsupported(t, &Postgres{}, Not(IsNot(f, f)), "IS")
+	testUnsupported(t, &Postgres{}, Glob(f, f), "GLOB")
+}
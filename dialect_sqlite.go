@@ -0,0 +1,159 @@
+package flexsql
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+type SQLite struct{}
+
+func (s *SQLite) QuoteIdentifier(i string) string {
+	return `"` + strings.Replace(i, `"`, `""`, -1) + `"`
+}
+
+func (s *SQLite) MakePlaceholder(name string, position uint) string {
+	return fmt.Sprintf("?%d", position+1)
+}
+
+func (s *SQLite) Precedence(op OperatorType) uint {
+	switch op {
+	case OpOr:
+		return 1
+	case OpAnd:
+		return 2
+	case OpNot, OpNotExists:
+		return 3
+	case OpEq, OpNotEq, OpIs, OpIsNot, OpIsNull, OpIsNotNull, OpIsTrue, OpIsNotTrue, OpIsFalse, OpIsNotFalse, OpIn, OpNotIn, OpLike, OpNotLike, OpGlob, OpNotGlob, OpBetween, OpNotBetween:
+		return 4
+	case OpLt, OpGt, OpLte, OpGte:
+		return 5
+	case OpAdd, OpSub:
+		return 7
+	case OpMul, OpDiv, OpMod:
+		return 8
+	case OpConcat:
+		return 9
+	case OpExists:
+		return 10
+	}
+	return 0
+}
+
+func (s *SQLite) Associativity(op OperatorType) Associativity {
+	switch op {
+	case OpOr, OpAnd, OpEq, OpNotEq, OpIs, OpIsNot, OpIsNull, OpIsNotNull, OpIsTrue, OpIsNotTrue, OpIsFalse, OpIsNotFalse, OpIn, OpNotIn, OpLike, OpNotLike, OpGlob, OpNotGlob, OpLt, OpGt, OpLte, OpGte, OpAdd, OpSub, OpMul, OpDiv, OpMod, OpConcat:
+		return LeftAssociative
+	case OpNot, OpExists, OpNotExists:
+		return RightAssociative
+	case OpBetween, OpNotBetween:
+		return NonAssociative
+	}
+	return 0
+}
+
+func (s *SQLite) Supports(f Feature) bool {
+	switch f {
+	case FeatureILike, FeatureNullsOrdering, FeatureDistinctOn,
+		FeatureQuantifiedSubquery, FeatureQuantifiedArray:
+		return false
+	}
+	return true
+}
+
+// CheckNode rejects DELETE ... USING, INTERSECT ALL and EXCEPT ALL,
+// which SQLite lacks, and parenthesized operands of set operations,
+// which SQLite cannot parse.
+func (s *SQLite) CheckNode(n Node) error {
+	construct := ""
+	switch v := n.(type) {
+	case *UsingClause:
+		construct = "DELETE ... USING"
+	case *SetOperation:
+		if v.All && v.Type != SetOpUnion {
+			construct = v.Type.String() + " ALL"
+		} else if v.needsParen(v.Left, false) || v.needsParen(v.Right, true) {
+			construct = "parenthesized " + v.Type.String() + " operand"
+		}
+	}
+	if construct != "" {
+		return &UnsupportedError{
+			Dialect:   s,
+			Construct: construct,
+		}
+	}
+	return nil
+}
+
+// Rewrite emulates ILIKE with LIKE on lower-cased operands.
+func (s *SQLite) Rewrite(n Node) Node {
+	if b, ok := n.(*BinaryOperator); ok {
+		return s.rewriteILike(b)
+	}
+	return n
+}
+
+func (s *SQLite) rewriteILike(b *BinaryOperator) Node {
+	lower := Func("lower")
+	switch b.Type {
+	case OpILike:
+		return Like(lower(b.Left), lower(b.Right))
+	case OpNotILike:
+		return NotLike(lower(b.Left), lower(b.Right))
+	}
+	return b
+}
+
+func (s *SQLite) NullsFirstByDefault() bool {
+	return true
+}
+
+// NullsSortKey returns expr IS NULL, which is 1 or 0 in SQLite.
+func (s *SQLite) NullsSortKey(expr Expr) Expr {
+	return IsNull(expr)
+}
+
+// RenderNode renders BoolLiteral as 1 and 0 for older SQLite versions and
+// TimeLiteral as text, which is how the date and time functions of
+// SQLite take timestamps. LabeledTable is rendered with AS, without which
+// SQLite rejects an alias in UPDATE and DELETE. A NUL in StringLiteral
+// is rejected as SQLite drivers pass the SQL text as a C string.
+func (s *SQLite) RenderNode(c *Compiler, n Node) (bool, error) {
+	switch v := n.(type) {
+	case StringLiteral:
+		if strings.ContainsRune(string(v), 0) {
+			return true, &UnsupportedError{
+				Dialect:   s,
+				Construct: "NUL in string literal",
+			}
+		}
+		return false, nil
+	case *LabeledTable:
+		if v.Schema != "" {
+			c.WriteIdentifier(v.Schema)
+			c.WriteVerbatim(".")
+		}
+		c.WriteIdentifier(v.Name)
+		if v.Label != "" {
+			c.WriteVerbatim(" AS ")
+			c.WriteIdentifier(v.Label)
+		}
+		return true, nil
+	case BoolLiteral:
+		if v {
+			c.WriteVerbatim("1")
+		} else {
+			c.WriteVerbatim("0")
+		}
+		return true, nil
+	case TimeLiteral:
+		c.WriteVerbatim(quoteString(time.Time(v).Format(timeLiteralLayout)))
+		return true, nil
+	}
+	return false, nil
+}
+
+// MaxParameters is the default SQLITE_MAX_VARIABLE_NUMBER since SQLite 3.32.0.
+func (s *SQLite) MaxParameters() int {
+	return 32766
+}
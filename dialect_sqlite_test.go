@@ -0,0 +1,85 @@
+package flexsql
+
+import (
+	"testing"
+)
+
+func TestSQLiteQuoteIdentifier(t *testing.T) {
+	s := SQLite{}
+	cases := [][]string{
+		{"a", `"a"`},
+		{"1a", `"1a"`},
+		{`a"b`, `"a""b"`},
+		{"日本語", `"日本語"`},
+	}
+	for _, case_ := range cases {
+		input := case_[0]
+		expected := case_[1]
+		actual := s.QuoteIdentifier(input)
+		testEqual(t, actual, expected)
+	}
+}
+
+func TestSQLiteMakePlaceholder(t *testing.T) {
+	s := SQLite{}
+	testEqual(t, s.MakePlaceholder("unimportant", 0), "?1")
+	testEqual(t, s.MakePlaceholder("unimportant", 9), "?10")
+}
+
+func TestSQLiteOperator(t *testing.T) {
+	f := literal("f")
+	cases := []compileTest{
+		{Eq(Lt(f, f), f), "f < f = f"},
+		{Lt(Eq(f, f), f), "(f = f) < f"},
+		{Eq(Eq(f, f), f), "f = f = f"},
+		{Is(f, Eq(f, f)), "f IS (f = f)"},
+		{Not(Is(f, f)), "f IS NOT f"},
+		{Not(Glob(f, f)), "f NOT GLOB f"},
+		{Glob(Concat(f, f), f), "f || f GLOB f"},
+		{Mul(Concat(f, f), f), "f || f * f"},
+		{Concat(Mul(f, f), f), "(f * f) || f"},
+		{Eq(Placeholder("a"), Placeholder("a")), "?1 = ?2"},
+	}
+	for _, case_ := range cases {
+		testCompileDialect(t, &SQLite{}, case_.in, case_.out)
+	}
+}
+
+func TestSQLiteRewrite(t *testing.T) {
+	f := &Column{"", "f"}
+	cases := []compileTest{
+		{ILike(f, f), `lower("f") LIKE lower("f")`},
+		{Not(ILike(f, f)), `lower("f") NOT LIKE lower("f")`},
+		{NotILike(f, f), `lower("f") NOT LIKE lower("f")`},
+		{OrderBy(NullsFirst(Asc(f))), `ORDER BY "f"`},
+		{OrderBy(NullsLast(Desc(f))), `ORDER BY "f" DESC`},
+		{OrderBy(NullsLast(Asc(f))), `ORDER BY "f" IS NULL,"f"`},
+		{OrderBy(NullsFirst(Desc(f))), `ORDER BY "f" IS NULL DESC,"f" DESC`},
+		{OrderBy(Asc(f), NullsLast(Asc(f))), `ORDER BY "f","f" IS NULL,"f"`},
+	}
+	for _, case_ := range cases {
+		testCompileDialect(t, &SQLite{}, case_.in, case_.out)
+	}
+
+	// The ORDER BY of a set operation may only refer to output columns.
+	a := &SelectStmt{Columns: []*LabeledColumn{{f, "f"}}}
+	u := Union(a, a)
+	u.OrderByClause = OrderBy(NullsFirst(Asc(f)))
+	testCompileDialect(t, &SQLite{}, u, `SELECT "f" "f" UNION SELECT "f" "f" ORDER BY "f"`)
+	u.OrderByClause = OrderBy(NullsLast(Asc(f)))
+	testUnsupported(t, &SQLite{}, u, "NULLS FIRST/LAST")
+}
+
+func TestSQLiteSetOperation(t *testing.T) {
+	a := &SelectStmt{Columns: []*LabeledColumn{{Placeholder("a"), "a"}}}
+	b := &SelectStmt{Columns: []*LabeledColumn{{Placeholder("b"), "b"}}}
+	limited := &SelectStmt{
+		Columns:     []*LabeledColumn{{Placeholder("b"), "b"}},
+		LimitClause: &LimitClause{Placeholder("limit")},
+	}
+	testCompileDialect(t, &SQLite{}, UnionAll(Union(a, b), a), `SELECT ?1 "a" UNION SELECT ?2 "b" UNION ALL SELECT ?3 "a"`)
+	testUnsupported(t, &SQLite{}, IntersectAll(a, b), "INTERSECT ALL")
+	testUnsupported(t, &SQLite{}, ExceptAll(a, b), "EXCEPT ALL")
+	testUnsupported(t, &SQLite{}, Union(a, limited), "parenthesized UNION operand")
+	testUnsupported(t, &SQLite{}, Intersect(Union(a, b), a), "parenthesized INTERSECT operand")
+}
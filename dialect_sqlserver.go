@@ -0,0 +1,365 @@
+package flexsql
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+var (
+	ErrOrderByRequired = errors.New("ORDER BY is required")
+)
+
+type SQLServer struct{}
+
+func (s *SQLServer) QuoteIdentifier(i string) string {
+	return "[" + strings.Replace(i, "]", "]]", -1) + "]"
+}
+
+func (s *SQLServer) MakePlaceholder(name string, position uint) string {
+	return fmt.Sprintf("@p%d", position+1)
+}
+
+func (s *SQLServer) Precedence(op OperatorType) uint {
+	switch op {
+	case OpOr:
+		return 1
+	case OpAnd:
+		return 2
+	case OpNot, OpNotExists:
+		return 3
+	case OpLt, OpGt, OpEq, OpLte, OpGte, OpNotEq, OpIsNull, OpIsNotNull, OpBetween, OpNotBetween, OpIn, OpNotIn, OpLike, OpNotLike:
+		return 4
+	case OpAdd, OpSub:
+		return 7
+	case OpMul, OpDiv, OpMod:
+		return 8
+	case OpExists:
+		return 9
+	}
+	return 0
+}
+
+func (s *SQLServer) Associativity(op OperatorType) Associativity {
+	switch op {
+	case OpIsNull, OpIsNotNull, OpOr, OpAnd, OpAdd, OpSub, OpMul, OpDiv, OpMod:
+		return LeftAssociative
+	case OpNot, OpExists, OpNotExists:
+		return RightAssociative
+	case OpLt, OpGt, OpEq, OpLte, OpGte, OpNotEq, OpBetween, OpNotBetween, OpIn, OpNotIn, OpLike, OpNotLike:
+		return NonAssociative
+	}
+	return 0
+}
+
+func (s *SQLServer) Supports(f Feature) bool {
+	switch f {
+	case FeatureILike, FeatureNullsOrdering, FeatureCTEMaterialization, FeatureDistinctOn,
+		FeatureAggregateFilter, FeatureGroupsFrame, FeatureQuantifiedArray,
+		FeatureConcat, FeatureIs, FeatureGlob:
+		return false
+	}
+	return true
+}
+
+// RenderRowLimit renders OFFSET ... ROWS FETCH NEXT ... ROWS ONLY,
+// which SQL Server only allows after ORDER BY.
+func (s *SQLServer) RenderRowLimit(c *Compiler, orderBy *OrderByClause, limit *LimitClause, offset *OffsetClause) error {
+	if orderBy == nil {
+		return ErrOrderByRequired
+	}
+	c.WriteVerbatim("OFFSET ")
+	if offset != nil {
+		if err := c.WriteNode(offset.Expr); err != nil {
+			return err
+		}
+	} else {
+		c.WriteVerbatim("0")
+	}
+	c.WriteVerbatim(" ROWS")
+	if limit != nil {
+		c.WriteVerbatim(" FETCH NEXT ")
+		if err := c.WriteNode(limit.Expr); err != nil {
+			return err
+		}
+		c.WriteVerbatim(" ROWS ONLY")
+	}
+	return nil
+}
+
+// CheckNode rejects RETURNING, ON CONFLICT, INTERSECT ALL and EXCEPT ALL.
+// SQL Server has OUTPUT instead of RETURNING, which appears at a different
+// position in the statement, and MERGE instead of ON CONFLICT.
+func (s *SQLServer) CheckNode(n Node) error {
+	construct := ""
+	switch v := n.(type) {
+	case *ReturningClause:
+		construct = "RETURNING"
+	case *OnConflictClause:
+		construct = "ON CONFLICT"
+	case *SetOperation:
+		if v.All && v.Type != SetOpUnion {
+			construct = v.Type.String() + " ALL"
+		}
+	}
+	if construct != "" {
+		return &UnsupportedError{
+			Dialect:   s,
+			Construct: construct,
+		}
+	}
+	return nil
+}
+
+// RenderNode renders SQLType with the names of SQL Server.
+// In particular TIMESTAMP is a row version in SQL Server.
+// StringLiteral is rendered as a Unicode string, BoolLiteral as
+// a bit, BytesLiteral as a binary constant and TimeLiteral as DATETIME2.
+// A NUL in StringLiteral is rejected as drivers may cut the SQL text there.
+// UpdateStmt and DeleteStmt are rendered with the table in FROM, since
+// SQL Server does not take a table alias after UPDATE or DELETE FROM.
+func (s *SQLServer) RenderNode(c *Compiler, n Node) (bool, error) {
+	switch v := n.(type) {
+	case SQLType:
+		return s.renderSQLType(c, v)
+	case *UpdateStmt:
+		return true, s.renderUpdate(c, v)
+	case *DeleteStmt:
+		return true, s.renderDelete(c, v)
+	case StringLiteral:
+		if strings.ContainsRune(string(v), 0) {
+			return true, &UnsupportedError{
+				Dialect:   s,
+				Construct: "NUL in string literal",
+			}
+		}
+		c.WriteVerbatim("N")
+		c.WriteVerbatim(quoteString(string(v)))
+		return true, nil
+	case BoolLiteral:
+		if v {
+			c.WriteVerbatim("1")
+		} else {
+			c.WriteVerbatim("0")
+		}
+		return true, nil
+	case *BytesLiteral:
+		c.WriteVerbatim("0x")
+		c.WriteVerbatim(hex.EncodeToString(v.Bytes))
+		return true, nil
+	case TimeLiteral:
+		c.WriteVerbatim("CAST(")
+		c.WriteVerbatim(quoteString(time.Time(v).Format("2006-01-02T15:04:05.9999999")))
+		c.WriteVerbatim(" AS DATETIME2)")
+		return true, nil
+	}
+	return false, nil
+}
+
+// renderUpdate renders UPDATE alias SET ... FROM table alias, followed by
+// the items of FromClause. A table without alias is updated by its name.
+func (s *SQLServer) renderUpdate(c *Compiler, u *UpdateStmt) error {
+	if u.WhereClause == nil && c.requireWhere {
+		return ErrMissingWhereClause
+	}
+	if err := stringifyWithClause(u.WithClause, c); err != nil {
+		return err
+	}
+	c.WriteVerbatim("UPDATE ")
+	if u.Table.Label == "" {
+		if err := c.WriteNode(u.Table); err != nil {
+			return err
+		}
+	} else {
+		c.WriteIdentifier(u.Table.Label)
+	}
+	c.writeClauseSeparator()
+	c.WriteVerbatim("SET ")
+	if err := stringifyAssignments(u.Assignments, c); err != nil {
+		return err
+	}
+	if u.Table.Label != "" {
+		c.writeClauseSeparator()
+		c.WriteVerbatim("FROM ")
+		if err := c.WriteNode(u.Table); err != nil {
+			return err
+		}
+		if u.FromClause != nil {
+			c.WriteVerbatim(",")
+			if err := c.WriteNode(u.FromClause.FromClauseItem); err != nil {
+				return err
+			}
+		}
+	} else if u.FromClause != nil {
+		c.writeClauseSeparator()
+		if err := c.WriteNode(u.FromClause); err != nil {
+			return err
+		}
+	}
+	if u.WhereClause != nil {
+		c.writeClauseSeparator()
+		if err := c.WriteNode(u.WhereClause); err != nil {
+			return err
+		}
+	}
+	if u.ReturningClause != nil {
+		c.writeClauseSeparator()
+		return c.WriteNode(u.ReturningClause)
+	}
+	return nil
+}
+
+// renderDelete renders DELETE alias FROM table alias, followed by
+// the item of UsingClause. A table without alias is deleted from
+// by its name.
+func (s *SQLServer) renderDelete(c *Compiler, d *DeleteStmt) error {
+	if d.WhereClause == nil && c.requireWhere {
+		return ErrMissingWhereClause
+	}
+	if err := stringifyWithClause(d.WithClause, c); err != nil {
+		return err
+	}
+	c.WriteVerbatim("DELETE ")
+	if d.Table.Label != "" {
+		c.WriteIdentifier(d.Table.Label)
+		c.WriteVerbatim(" ")
+	} else if d.UsingClause != nil {
+		if err := c.WriteNode(d.Table); err != nil {
+			return err
+		}
+		c.WriteVerbatim(" ")
+	}
+	c.WriteVerbatim("FROM ")
+	if err := c.WriteNode(d.Table); err != nil {
+		return err
+	}
+	if d.UsingClause != nil {
+		c.WriteVerbatim(",")
+		if err := c.WriteNode(d.UsingClause.FromClauseItem); err != nil {
+			return err
+		}
+	}
+	if d.WhereClause != nil {
+		c.writeClauseSeparator()
+		if err := c.WriteNode(d.WhereClause); err != nil {
+			return err
+		}
+	}
+	if d.ReturningClause != nil {
+		c.writeClauseSeparator()
+		return c.WriteNode(d.ReturningClause)
+	}
+	return nil
+}
+
+func (s *SQLServer) renderSQLType(c *Compiler, sqlType SQLType) (bool, error) {
+	switch sqlType {
+	case Boolean:
+		c.WriteVerbatim("BIT")
+	case DoublePrecision:
+		c.WriteVerbatim("FLOAT")
+	case Text:
+		c.WriteVerbatim("NVARCHAR(MAX)")
+	case Timestamp:
+		c.WriteVerbatim("DATETIME2")
+	default:
+		return false, nil
+	}
+	return true, nil
+}
+
+// Rewrite drops RECURSIVE from WITH, which SQL Server omits
+// even for recursive common table expressions, and replaces
+// empty InList and BoolLiteral used as a predicate with a comparison,
+// since SQL Server has no boolean constants.
+func (s *SQLServer) Rewrite(n Node) Node {
+	switch v := n.(type) {
+	case *WithClause:
+		if v.Recursive {
+			return &WithClause{CTEs: v.CTEs}
+		}
+	case *InList:
+		if v.IsEmpty() {
+			predicate, _ := s.rewritePredicate(BoolLiteral(v.Negated))
+			return predicate
+		}
+	case *WhereClause:
+		if predicate, ok := s.rewritePredicate(v.Expr); ok {
+			return &WhereClause{predicate}
+		}
+	case *HavingClause:
+		if predicate, ok := s.rewritePredicate(v.Expr); ok {
+			return &HavingClause{predicate}
+		}
+	case *UnaryOperator:
+		if v.Type == OpNot {
+			if predicate, ok := s.rewritePredicate(v.Expr); ok {
+				copied := *v
+				copied.Expr = predicate
+				return &copied
+			}
+		}
+	case *BinaryOperator:
+		if v.Type == OpAnd || v.Type == OpOr {
+			left, leftChanged := s.rewritePredicate(v.Left)
+			right, rightChanged := s.rewritePredicate(v.Right)
+			if leftChanged || rightChanged {
+				copied := *v
+				copied.Left = left
+				copied.Right = right
+				return &copied
+			}
+		}
+	case *JoinClause:
+		if predicate, ok := s.rewritePredicate(v.on); ok {
+			copied := *v
+			copied.on = predicate
+			return &copied
+		}
+	case *CaseExpr:
+		var conds []Expr
+		for i, cond := range v.conds {
+			predicate, ok := s.rewritePredicate(cond)
+			if ok && conds == nil {
+				conds = make([]Expr, len(v.conds))
+				copy(conds, v.conds)
+			}
+			if conds != nil {
+				conds[i] = predicate
+			}
+		}
+		if conds != nil {
+			return &CaseExpr{conds: conds, results: v.results, else_: v.else_}
+		}
+	}
+	return n
+}
+
+// rewritePredicate replaces BoolLiteral with 1 = 1 or 1 = 0
+// and reports whether e was replaced.
+func (s *SQLServer) rewritePredicate(e Expr) (Expr, bool) {
+	b, ok := e.(BoolLiteral)
+	if !ok {
+		return e, false
+	}
+	if b {
+		return Eq(IntLiteral(1), IntLiteral(1)), true
+	}
+	return Eq(IntLiteral(1), IntLiteral(0)), true
+}
+
+func (s *SQLServer) NullsFirstByDefault() bool {
+	return true
+}
+
+// NullsSortKey returns a CASE expression as predicates
+// are not expressions in SQL Server.
+func (s *SQLServer) NullsSortKey(expr Expr) Expr {
+	return caseNullsSortKey(expr)
+}
+
+func (s *SQLServer) MaxParameters() int {
+	return 2100
+}
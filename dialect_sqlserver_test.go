@@ -0,0 +1,146 @@
+package flexsql
+
+import (
+	"testing"
+)
+
+func TestSQLServerQuoteIdentifier(t *testing.T) {
+	s := SQLServer{}
+	cases := [][]string{
+		{"a", "[a]"},
+		{"1a", "[1a]"},
+		{"a]b", "[a]]b]"},
+		{"日本語", "[日本語]"},
+	}
+	for _, case_ := range cases {
+		input := case_[0]
+		expected := case_[1]
+		actual := s.QuoteIdentifier(input)
+		testEqual(t, actual, expected)
+	}
+}
+
+func TestSQLServerMakePlaceholder(t *testing.T) {
+	s := SQLServer{}
+	testEqual(t, s.MakePlaceholder("unimportant", 0), "@p1")
+	testEqual(t, s.MakePlaceholder("unimportant", 9), "@p10")
+}
+
+func TestSQLServerRowLimit(t *testing.T) {
+	sel := &SelectStmt{
+		Columns: []*LabeledColumn{
+			&LabeledColumn{&Column{"t", "a"}, "a"},
+		},
+		FromClause: &FromClause{&FromClauseItem{
+			TableRef: &LabeledTable{Name: "t", Label: "t"},
+		}},
+	}
+	testCompileDialect(t, &SQLServer{}, sel, `SELECT [t].[a] [a] FROM [t] [t]`)
+
+	sel.LimitClause = &LimitClause{Placeholder("limit")}
+	c := NewCompiler(&SQLServer{})
+	_, err := c.Compile(sel)
+	testEqual(t, err, ErrOrderByRequired)
+
+	sel.OrderByClause = OrderBy(Asc(&Column{"t", "a"}))
+	testCompileDialect(t, &SQLServer{}, sel, `SELECT [t].[a] [a] FROM [t] [t] ORDER BY [t].[a] OFFSET 0 ROWS FETCH NEXT @p1 ROWS ONLY`)
+
+	sel.OffsetClause = &OffsetClause{Placeholder("offset")}
+	testCompileDialect(t, &SQLServer{}, sel, `SELECT [t].[a] [a] FROM [t] [t] ORDER BY [t].[a] OFFSET @p1 ROWS FETCH NEXT @p2 ROWS ONLY`)
+
+	sel.LimitClause = nil
+	testCompileDialect(t, &SQLServer{}, sel, `SELECT [t].[a] [a] FROM [t] [t] ORDER BY [t].[a] OFFSET @p1 ROWS`)
+}
+
+func TestSQLServerOperator(t *testing.T) {
+	f := literal("f")
+	cases := []compileTest{
+		{And(Eq(f, f), Or(f, f)), "f = f AND (f OR f)"},
+		{Not(Like(f, f)), "f NOT LIKE f"},
+		{Add(f, Mul(f, f)), "f + f * f"},
+	}
+	for _, case_ := range cases {
+		testCompileDialect(t, &SQLServer{}, case_.in, case_.out)
+	}
+	testUnsupported(t, &SQLServer{}, ILike(f, f), "ILIKE")
+	testUnsupported(t, &SQLServer{}, Concat(f, f), "||")
+	testUnsupported(t, &SQLServer{}, IsNot(f, f), "IS")
+	testUnsupported(t, &SQLServer{}, Not(Glob(f, f)), "GLOB")
+}
+
+func TestSQLServerNullsOrdering(t *testing.T) {
+	f := &Column{"", "f"}
+	cases := []compileTest{
+		{OrderBy(NullsFirst(Asc(f))), `ORDER BY [f]`},
+		{OrderBy(NullsLast(Asc(f))), `ORDER BY CASE WHEN [f] IS NULL THEN 1 ELSE 0 END,[f]`},
+		{OrderBy(Asc(f), NullsFirst(Desc(Not(Not(f))))), `ORDER BY [f],CASE WHEN [f] IS NULL THEN 1 ELSE 0 END DESC,[f] DESC`},
+		{
+			Func("row_number")().Over(&WindowSpec{OrderByClause: OrderBy(NullsLast(Asc(f)))}),
+			`row_number() OVER (ORDER BY CASE WHEN [f] IS NULL THEN 1 ELSE 0 END,[f])`,
+		},
+	}
+	for _, case_ := range cases {
+		testCompileDialect(t, &SQLServer{}, case_.in, case_.out)
+	}
+
+	// The ORDER BY of DISTINCT and set operations may only refer to
+	// output columns, so the sort key cannot be added.
+	sel := &SelectStmt{
+		Columns:       []*LabeledColumn{{f, "f"}},
+		OrderByClause: OrderBy(NullsLast(Asc(f))),
+	}
+	testCompileDialect(t, &SQLServer{}, sel, `SELECT [f] [f] ORDER BY CASE WHEN [f] IS NULL THEN 1 ELSE 0 END,[f]`)
+	sel.Distinct = true
+	testUnsupported(t, &SQLServer{}, sel, "NULLS FIRST/LAST")
+	sel.OrderByClause = OrderBy(NullsFirst(Asc(f)))
+	testCompileDialect(t, &SQLServer{}, sel, `SELECT DISTINCT [f] [f] ORDER BY [f]`)
+
+	u := UnionAll(&SelectStmt{Columns: []*LabeledColumn{{f, "f"}}}, &SelectStmt{Columns: []*LabeledColumn{{f, "f"}}})
+	u.OrderByClause = OrderBy(NullsLast(Asc(f)))
+	testUnsupported(t, &SQLServer{}, u, "NULLS FIRST/LAST")
+}
+
+func TestSQLServerRenderNode(t *testing.T) {
+	a := literal("a")
+	cases := []compileTest{
+		{Cast(a, Boolean), "CAST(a AS BIT)"},
+		{Cast(a, Timestamp), "CAST(a AS DATETIME2)"},
+		{Cast(a, Text), "CAST(a AS NVARCHAR(MAX))"},
+		{Cast(a, Integer), "CAST(a AS INTEGER)"},
+	}
+	for _, case_ := range cases {
+		testCompileDialect(t, &SQLServer{}, case_.in, case_.out)
+	}
+}
+
+func TestSQLServerBoolPredicate(t *testing.T) {
+	a := &Column{"t", "a"}
+	sel := &SelectStmt{
+		Columns:     []*LabeledColumn{{BoolLiteral(true), "b"}},
+		FromClause:  &FromClause{&FromClauseItem{TableRef: &LabeledTable{Name: "t", Label: "t"}}},
+		WhereClause: &WhereClause{BoolLiteral(true)},
+	}
+	testCompileDialect(t, &SQLServer{}, sel, `SELECT 1 [b] FROM [t] [t] WHERE 1 = 1`)
+
+	cases := []compileTest{
+		{And(Eq(a, a), BoolLiteral(false)), `[t].[a] = [t].[a] AND 1 = 0`},
+		{Or(BoolLiteral(false), BoolLiteral(true)), `1 = 0 OR 1 = 1`},
+		{Not(BoolLiteral(true)), `NOT 1 = 1`},
+		{Case(BoolLiteral(true), a), `CASE WHEN 1 = 1 THEN [t].[a] END`},
+		{Eq(a, BoolLiteral(true)), `[t].[a] = 1`},
+	}
+	for _, case_ := range cases {
+		testCompileDialect(t, &SQLServer{}, case_.in, case_.out)
+	}
+}
+
+func TestSQLServerSetOperation(t *testing.T) {
+	a := &SelectStmt{Columns: []*LabeledColumn{{Placeholder("a"), "a"}}}
+	b := &SelectStmt{Columns: []*LabeledColumn{{Placeholder("b"), "b"}}}
+	u := UnionAll(a, b)
+	u.OrderByClause = OrderBy(Asc(&Column{"", "a"}))
+	u.LimitClause = &LimitClause{Placeholder("limit")}
+	testCompileDialect(t, &SQLServer{}, u, `SELECT @p1 [a] UNION ALL SELECT @p2 [b] ORDER BY [a] OFFSET 0 ROWS FETCH NEXT @p3 ROWS ONLY`)
+	testCompileDialect(t, &SQLServer{}, Except(a, b), `SELECT @p1 [a] EXCEPT SELECT @p2 [b]`)
+	testUnsupported(t, &SQLServer{}, IntersectAll(a, b), "INTERSECT ALL")
+}
@@ -0,0 +1,585 @@
+package flexsql
+
+import (
+	"errors"
+)
+
+var (
+	ErrInvalidInsertSource   = errors.New("Insert requires one of Values, SelectStmt or SetOperation")
+	ErrValuesLengthMismatch  = errors.New("Values length mismatch")
+	ErrMissingWhereClause    = errors.New("Missing WHERE clause")
+	ErrMissingConflictTarget = errors.New("DO UPDATE requires conflict target")
+)
+
+// InsertStmt is INSERT INTO followed by either VALUES or a SELECT statement.
+// Columns may be empty to insert into all columns in table order.
+type InsertStmt struct {
+	WithClause       *WithClause
+	Table            *Table
+	Columns          []string
+	Values           [][]Expr
+	SelectStmt       *SelectStmt
+	SetOperation     *SetOperation
+	OnConflictClause *OnConflictClause
+	ReturningClause  *ReturningClause
+}
+
+func (i *InsertStmt) Transform(c *Compiler) Node {
+	copied := *i
+	changed := false
+
+	if i.WithClause != nil {
+		if n, ok := transformNode(i.WithClause, c); ok {
+			copied.WithClause = n.(*WithClause)
+			changed = true
+		}
+	}
+
+	var values [][]Expr
+	for j, row := range i.Values {
+		t, rowChanged := transformExprs(row, c)
+		if rowChanged && values == nil {
+			values = make([][]Expr, len(i.Values))
+			copy(values, i.Values)
+		}
+		if values != nil {
+			values[j] = t
+		}
+	}
+	if values != nil {
+		copied.Values = values
+		changed = true
+	}
+	if n, ok := transformNode(i.Table, c); ok {
+		copied.Table = n.(*Table)
+		changed = true
+	}
+	if i.SelectStmt != nil {
+		if n, ok := transformNode(i.SelectStmt, c); ok {
+			copied.SelectStmt = n.(*SelectStmt)
+			changed = true
+		}
+	}
+	if i.SetOperation != nil {
+		if n, ok := transformNode(i.SetOperation, c); ok {
+			copied.SetOperation = n.(*SetOperation)
+			changed = true
+		}
+	}
+	if i.OnConflictClause != nil {
+		if n, ok := transformNode(i.OnConflictClause, c); ok {
+			copied.OnConflictClause = n.(*OnConflictClause)
+			changed = true
+		}
+	}
+	if i.ReturningClause != nil {
+		if n, ok := transformNode(i.ReturningClause, c); ok {
+			copied.ReturningClause = n.(*ReturningClause)
+			changed = true
+		}
+	}
+
+	if !changed {
+		return i
+	}
+	return &copied
+}
+
+func (i *InsertStmt) Stringify(c *Compiler) error {
+	sources := 0
+	for _, ok := range []bool{len(i.Values) > 0, i.SelectStmt != nil, i.SetOperation != nil} {
+		if ok {
+			sources++
+		}
+	}
+	if sources != 1 {
+		return ErrInvalidInsertSource
+	}
+
+	if err := stringifyWithClause(i.WithClause, c); err != nil {
+		return err
+	}
+	c.WriteVerbatim("INSERT INTO ")
+	if err := c.WriteNode(i.Table); err != nil {
+		return err
+	}
+	if len(i.Columns) > 0 {
+		c.WriteVerbatim(" ")
+		stringifyIdentifierList(i.Columns, c)
+	}
+
+	c.writeClauseSeparator()
+	if i.SelectStmt != nil {
+		if err := c.WriteNode(i.SelectStmt); err != nil {
+			return err
+		}
+	} else if i.SetOperation != nil {
+		if err := c.WriteNode(i.SetOperation); err != nil {
+			return err
+		}
+	} else {
+		if err := i.stringifyValues(c); err != nil {
+			return err
+		}
+	}
+
+	if i.OnConflictClause != nil {
+		c.writeClauseSeparator()
+		if err := c.WriteNode(i.OnConflictClause); err != nil {
+			return err
+		}
+	}
+	if i.ReturningClause != nil {
+		c.writeClauseSeparator()
+		if err := c.WriteNode(i.ReturningClause); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (i *InsertStmt) stringifyValues(c *Compiler) error {
+	c.WriteVerbatim("VALUES ")
+	length := len(i.Values[0])
+	if len(i.Columns) > 0 {
+		length = len(i.Columns)
+	}
+	for j, row := range i.Values {
+		if len(row) != length || length <= 0 {
+			return ErrValuesLengthMismatch
+		}
+		if j > 0 {
+			c.WriteVerbatim(",")
+		}
+		c.WriteVerbatim("(")
+		if err := stringifyCommaSeparated(row, c); err != nil {
+			return err
+		}
+		c.WriteVerbatim(")")
+	}
+	return nil
+}
+
+// Assignment is column = expr in the SET clause of UPDATE.
+type Assignment struct {
+	Column string
+	Expr   Expr
+}
+
+func Set(column string, expr Expr) *Assignment {
+	return &Assignment{
+		Column: column,
+		Expr:   expr,
+	}
+}
+
+func (a *Assignment) Transform(c *Compiler) Node {
+	expr, changed := transformNode(a.Expr, c)
+	if !changed {
+		return a
+	}
+	return &Assignment{
+		Column: a.Column,
+		Expr:   expr,
+	}
+}
+
+func (a *Assignment) Stringify(c *Compiler) error {
+	c.WriteIdentifier(a.Column)
+	c.WriteVerbatim(" = ")
+	return c.WriteNode(a.Expr)
+}
+
+func transformAssignments(assignments []*Assignment, c *Compiler) ([]*Assignment, bool) {
+	var output []*Assignment
+	for i, a := range assignments {
+		t, changed := transformNode(a, c)
+		if changed && output == nil {
+			output = make([]*Assignment, len(assignments))
+			copy(output, assignments)
+		}
+		if output != nil {
+			output[i] = t.(*Assignment)
+		}
+	}
+	if output == nil {
+		return assignments, false
+	}
+	return output, true
+}
+
+func stringifyAssignments(assignments []*Assignment, c *Compiler) error {
+	if len(assignments) <= 0 {
+		return ErrZeroLength
+	}
+	nodes := make([]Node, len(assignments))
+	for i, a := range assignments {
+		nodes[i] = a
+	}
+	return stringifyCommaSeparated(nodes, c)
+}
+
+// ReturningClause is RETURNING of INSERT, UPDATE and DELETE.
+// Label the columns with the Struct_Field convention, e.g. with
+// LabeledColumns, to scan the returned rows with Mapper.
+type ReturningClause struct {
+	Columns []*LabeledColumn
+}
+
+func Returning(first *LabeledColumn, rest ...*LabeledColumn) *ReturningClause {
+	columns := make([]*LabeledColumn, 1+len(rest))
+	columns[0] = first
+	for i, v := range rest {
+		columns[i+1] = v
+	}
+	return &ReturningClause{columns}
+}
+
+func (r *ReturningClause) Transform(c *Compiler) Node {
+	var columns []*LabeledColumn
+	for i, v := range r.Columns {
+		t, changed := transformNode(v, c)
+		if changed && columns == nil {
+			columns = make([]*LabeledColumn, len(r.Columns))
+			copy(columns, r.Columns)
+		}
+		if columns != nil {
+			columns[i] = t.(*LabeledColumn)
+		}
+	}
+	if columns == nil {
+		return r
+	}
+	return &ReturningClause{columns}
+}
+
+func (r *ReturningClause) Stringify(c *Compiler) error {
+	if len(r.Columns) <= 0 {
+		return ErrZeroLength
+	}
+	c.WriteVerbatim("RETURNING ")
+	nodes := make([]Node, len(r.Columns))
+	for i, v := range r.Columns {
+		nodes[i] = v
+	}
+	return stringifyCommaSeparated(nodes, c)
+}
+
+type UpdateStmt struct {
+	WithClause      *WithClause
+	Table           *LabeledTable
+	Assignments     []*Assignment
+	FromClause      *FromClause
+	WhereClause     *WhereClause
+	ReturningClause *ReturningClause
+}
+
+func (u *UpdateStmt) Transform(c *Compiler) Node {
+	copied := *u
+	changed := false
+
+	if u.WithClause != nil {
+		if n, ok := transformNode(u.WithClause, c); ok {
+			copied.WithClause = n.(*WithClause)
+			changed = true
+		}
+	}
+	if n, ok := transformNode(u.Table, c); ok {
+		copied.Table = n.(*LabeledTable)
+		changed = true
+	}
+	if assignments, ok := transformAssignments(u.Assignments, c); ok {
+		copied.Assignments = assignments
+		changed = true
+	}
+	if u.FromClause != nil {
+		if n, ok := transformNode(u.FromClause, c); ok {
+			copied.FromClause = n.(*FromClause)
+			changed = true
+		}
+	}
+	if u.WhereClause != nil {
+		if n, ok := transformNode(u.WhereClause, c); ok {
+			copied.WhereClause = n.(*WhereClause)
+			changed = true
+		}
+	}
+	if u.ReturningClause != nil {
+		if n, ok := transformNode(u.ReturningClause, c); ok {
+			copied.ReturningClause = n.(*ReturningClause)
+			changed = true
+		}
+	}
+
+	if !changed {
+		return u
+	}
+	return &copied
+}
+
+func (u *UpdateStmt) Stringify(c *Compiler) error {
+	if u.WhereClause == nil && c.requireWhere {
+		return ErrMissingWhereClause
+	}
+	if err := stringifyWithClause(u.WithClause, c); err != nil {
+		return err
+	}
+	c.WriteVerbatim("UPDATE ")
+	if err := c.WriteNode(u.Table); err != nil {
+		return err
+	}
+	c.writeClauseSeparator()
+	c.WriteVerbatim("SET ")
+	if err := stringifyAssignments(u.Assignments, c); err != nil {
+		return err
+	}
+	if u.FromClause != nil {
+		c.writeClauseSeparator()
+		if err := c.WriteNode(u.FromClause); err != nil {
+			return err
+		}
+	}
+	if u.WhereClause != nil {
+		c.writeClauseSeparator()
+		if err := c.WriteNode(u.WhereClause); err != nil {
+			return err
+		}
+	}
+	if u.ReturningClause != nil {
+		c.writeClauseSeparator()
+		if err := c.WriteNode(u.ReturningClause); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type UsingClause struct {
+	FromClauseItem *FromClauseItem
+}
+
+func (u *UsingClause) Transform(c *Compiler) Node {
+	item, changed := transformNode(u.FromClauseItem, c)
+	if !changed {
+		return u
+	}
+	return &UsingClause{item.(*FromClauseItem)}
+}
+
+func (u *UsingClause) Stringify(c *Compiler) error {
+	c.WriteVerbatim("USING ")
+	return c.WriteNode(u.FromClauseItem)
+}
+
+type DeleteStmt struct {
+	WithClause      *WithClause
+	Table           *LabeledTable
+	UsingClause     *UsingClause
+	WhereClause     *WhereClause
+	ReturningClause *ReturningClause
+}
+
+func (d *DeleteStmt) Transform(c *Compiler) Node {
+	copied := *d
+	changed := false
+
+	if d.WithClause != nil {
+		if n, ok := transformNode(d.WithClause, c); ok {
+			copied.WithClause = n.(*WithClause)
+			changed = true
+		}
+	}
+	if n, ok := transformNode(d.Table, c); ok {
+		copied.Table = n.(*LabeledTable)
+		changed = true
+	}
+	if d.UsingClause != nil {
+		if n, ok := transformNode(d.UsingClause, c); ok {
+			copied.UsingClause = n.(*UsingClause)
+			changed = true
+		}
+	}
+	if d.WhereClause != nil {
+		if n, ok := transformNode(d.WhereClause, c); ok {
+			copied.WhereClause = n.(*WhereClause)
+			changed = true
+		}
+	}
+	if d.ReturningClause != nil {
+		if n, ok := transformNode(d.ReturningClause, c); ok {
+			copied.ReturningClause = n.(*ReturningClause)
+			changed = true
+		}
+	}
+
+	if !changed {
+		return d
+	}
+	return &copied
+}
+
+func (d *DeleteStmt) Stringify(c *Compiler) error {
+	if d.WhereClause == nil && c.requireWhere {
+		return ErrMissingWhereClause
+	}
+	if err := stringifyWithClause(d.WithClause, c); err != nil {
+		return err
+	}
+	c.WriteVerbatim("DELETE FROM ")
+	if err := c.WriteNode(d.Table); err != nil {
+		return err
+	}
+	if d.UsingClause != nil {
+		c.writeClauseSeparator()
+		if err := c.WriteNode(d.UsingClause); err != nil {
+			return err
+		}
+	}
+	if d.WhereClause != nil {
+		c.writeClauseSeparator()
+		if err := c.WriteNode(d.WhereClause); err != nil {
+			return err
+		}
+	}
+	if d.ReturningClause != nil {
+		c.writeClauseSeparator()
+		if err := c.WriteNode(d.ReturningClause); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// OnConflictClause is ON CONFLICT of INSERT. It is DO NOTHING
+// when Assignments is empty and DO UPDATE SET otherwise.
+// TargetWhereClause is the predicate of a partial unique index and
+// WhereClause restricts the rows to be updated.
+//
+// Example
+//
+//	stmt := &InsertStmt{
+//		Table:   &Table{Name: "counter"},
+//		Columns: []string{"id", "count"},
+//		Values:  [][]Expr{{Placeholder("id"), Placeholder("count")}},
+//		OnConflictClause: OnConflictDoUpdate([]string{"id"},
+//			Set("count", Add(&Column{"counter", "count"}, Excluded("count"))),
+//		),
+//	}
+type OnConflictClause struct {
+	Columns           []string
+	TargetWhereClause *WhereClause
+	Assignments       []*Assignment
+	WhereClause       *WhereClause
+}
+
+func OnConflictDoNothing(columns ...string) *OnConflictClause {
+	return &OnConflictClause{
+		Columns: columns,
+	}
+}
+
+func OnConflictDoUpdate(columns []string, first *Assignment, rest ...*Assignment) *OnConflictClause {
+	assignments := make([]*Assignment, 1+len(rest))
+	assignments[0] = first
+	for i, v := range rest {
+		assignments[i+1] = v
+	}
+	return &OnConflictClause{
+		Columns:     columns,
+		Assignments: assignments,
+	}
+}
+
+func (o *OnConflictClause) IsDoNothing() bool {
+	return len(o.Assignments) <= 0
+}
+
+func (o *OnConflictClause) Transform(c *Compiler) Node {
+	copied := *o
+	changed := false
+
+	if o.TargetWhereClause != nil {
+		if n, ok := transformNode(o.TargetWhereClause, c); ok {
+			copied.TargetWhereClause = n.(*WhereClause)
+			changed = true
+		}
+	}
+	if assignments, ok := transformAssignments(o.Assignments, c); ok {
+		copied.Assignments = assignments
+		changed = true
+	}
+	if o.WhereClause != nil {
+		if n, ok := transformNode(o.WhereClause, c); ok {
+			copied.WhereClause = n.(*WhereClause)
+			changed = true
+		}
+	}
+
+	if !changed {
+		return o
+	}
+	return &copied
+}
+
+func (o *OnConflictClause) Stringify(c *Compiler) error {
+	c.WriteVerbatim("ON CONFLICT")
+	if len(o.Columns) > 0 {
+		c.WriteVerbatim(" ")
+		stringifyIdentifierList(o.Columns, c)
+		if o.TargetWhereClause != nil {
+			c.WriteVerbatim(" ")
+			if err := c.WriteNode(o.TargetWhereClause); err != nil {
+				return err
+			}
+		}
+	}
+	if o.IsDoNothing() {
+		c.WriteVerbatim(" DO NOTHING")
+		return nil
+	}
+	if len(o.Columns) <= 0 {
+		return ErrMissingConflictTarget
+	}
+	c.WriteVerbatim(" DO UPDATE SET ")
+	if err := stringifyAssignments(o.Assignments, c); err != nil {
+		return err
+	}
+	if o.WhereClause != nil {
+		c.WriteVerbatim(" ")
+		if err := c.WriteNode(o.WhereClause); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ExcludedColumn refers to the value proposed for insertion
+// in OnConflictClause, i.e. EXCLUDED.column.
+type ExcludedColumn struct {
+	Name string
+}
+
+func Excluded(name string) *ExcludedColumn {
+	return &ExcludedColumn{name}
+}
+
+func (e *ExcludedColumn) Transform(c *Compiler) Node {
+	return e
+}
+
+func (e *ExcludedColumn) Stringify(c *Compiler) error {
+	c.WriteVerbatim("EXCLUDED.")
+	c.WriteIdentifier(e.Name)
+	return nil
+}
+
+func stringifyIdentifierList(identifiers []string, c *Compiler) {
+	c.WriteVerbatim("(")
+	for j, identifier := range identifiers {
+		if j > 0 {
+			c.WriteVerbatim(",")
+		}
+		c.WriteIdentifier(identifier)
+	}
+	c.WriteVerbatim(")")
+}
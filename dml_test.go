@@ -0,0 +1,307 @@
+package flexsql
+
+import (
+	"testing"
+)
+
+func TestInsertStmt(t *testing.T) {
+	a := Placeholder("a")
+	b := Placeholder("b")
+	table := &Table{"s", "t"}
+
+	cases := []compileTest{
+		{
+			&InsertStmt{
+				Table:   table,
+				Columns: []string{"a", "b"},
+				Values:  [][]Expr{{a, b}},
+			},
+			`INSERT INTO "s"."t" ("a","b") VALUES ($1,$2)`,
+		},
+		{
+			&InsertStmt{
+				Table:   table,
+				Columns: []string{"a", "b"},
+				Values:  [][]Expr{{a, b}, {b, Not(Not(a))}},
+			},
+			`INSERT INTO "s"."t" ("a","b") VALUES ($1,$2),($3,$4)`,
+		},
+		{
+			&InsertStmt{
+				Table:  table,
+				Values: [][]Expr{{a}},
+			},
+			`INSERT INTO "s"."t" VALUES ($1)`,
+		},
+		{
+			&InsertStmt{
+				Table:   table,
+				Columns: []string{"a"},
+				SelectStmt: &SelectStmt{
+					Columns: []*LabeledColumn{
+						&LabeledColumn{&Column{"u", "a"}, "a"},
+					},
+					FromClause: &FromClause{&FromClauseItem{
+						TableRef: &LabeledTable{Name: "u", Label: "u"},
+					}},
+					WhereClause: &WhereClause{Eq(&Column{"u", "b"}, b)},
+				},
+			},
+			`INSERT INTO "s"."t" ("a") SELECT "u"."a" "a" FROM "u" "u" WHERE "u"."b" = $1`,
+		},
+	}
+	testMany(t, cases)
+
+	testCompileDialect(t, &MySQL{}, &InsertStmt{
+		Table:   &Table{Name: "t"},
+		Columns: []string{"a", "b"},
+		Values:  [][]Expr{{a, b}, {b, a}},
+	}, "INSERT INTO `t` (`a`,`b`) VALUES (?,?),(?,?)")
+}
+
+func TestInsertStmtError(t *testing.T) {
+	table := &Table{Name: "t"}
+	sel := &SelectStmt{
+		Columns: []*LabeledColumn{
+			&LabeledColumn{literal("1"), "a"},
+		},
+	}
+	cases := []struct {
+		in  Node
+		err error
+	}{
+		{&InsertStmt{Table: table}, ErrInvalidInsertSource},
+		{&InsertStmt{Table: table, Values: [][]Expr{{literal("1")}}, SelectStmt: sel}, ErrInvalidInsertSource},
+		{&InsertStmt{Table: table, SelectStmt: sel, SetOperation: Union(sel, sel)}, ErrInvalidInsertSource},
+		{&InsertStmt{Table: table, Columns: []string{"a", "b"}, Values: [][]Expr{{literal("1")}}}, ErrValuesLengthMismatch},
+		{&InsertStmt{Table: table, Values: [][]Expr{{literal("1")}, {}}}, ErrValuesLengthMismatch},
+		{&InsertStmt{Table: table, Values: [][]Expr{{}}}, ErrValuesLengthMismatch},
+	}
+	for _, case_ := range cases {
+		c := NewCompiler(&Postgres{})
+		_, err := c.Compile(case_.in)
+		testEqual(t, err, case_.err)
+	}
+}
+
+func TestUpdateStmt(t *testing.T) {
+	a := Placeholder("a")
+	b := Placeholder("b")
+	table := &LabeledTable{Schema: "s", Name: "t", Label: "s_t"}
+
+	stmt := &UpdateStmt{
+		Table:       table,
+		Assignments: []*Assignment{Set("a", a)},
+	}
+	testCompile(t, stmt, `UPDATE "s"."t" "s_t" SET "a" = $1`)
+
+	stmt.Assignments = []*Assignment{
+		Set("a", a),
+		Set("b", Add(&Column{"s_t", "b"}, b)),
+	}
+	testCompile(t, stmt, `UPDATE "s"."t" "s_t" SET "a" = $1,"b" = "s_t"."b" + $2`)
+
+	stmt.FromClause = &FromClause{&FromClauseItem{
+		TableRef: &LabeledTable{Name: "u", Label: "u"},
+	}}
+	testCompile(t, stmt, `UPDATE "s"."t" "s_t" SET "a" = $1,"b" = "s_t"."b" + $2 FROM "u" "u"`)
+
+	stmt.WhereClause = &WhereClause{Eq(&Column{"s_t", "id"}, &Column{"u", "id"})}
+	testCompile(t, stmt, `UPDATE "s"."t" "s_t" SET "a" = $1,"b" = "s_t"."b" + $2 FROM "u" "u" WHERE "s_t"."id" = "u"."id"`)
+
+	stmt.ReturningClause = &ReturningClause{[]*LabeledColumn{
+		&LabeledColumn{&Column{"s_t", "a"}, "T_A"},
+	}}
+	testCompile(t, stmt, `UPDATE "s"."t" "s_t" SET "a" = $1,"b" = "s_t"."b" + $2 FROM "u" "u" WHERE "s_t"."id" = "u"."id" RETURNING "s_t"."a" "T_A"`)
+
+	testUnsupported(t, &MySQL{}, stmt, "UPDATE ... FROM")
+	stmt.FromClause = nil
+	testUnsupported(t, &MySQL{}, stmt, "RETURNING")
+	testUnsupported(t, &SQLServer{}, stmt, "RETURNING")
+	stmt.ReturningClause = nil
+	testCompileDialect(t, &MySQL{}, stmt, "UPDATE `s`.`t` `s_t` SET `a` = ?,`b` = `s_t`.`b` + ? WHERE `s_t`.`id` = `u`.`id`")
+	testCompileDialect(t, &SQLite{}, stmt, `UPDATE "s"."t" AS "s_t" SET "a" = ?1,"b" = "s_t"."b" + ?2 WHERE "s_t"."id" = "u"."id"`)
+	testCompileDialect(t, &SQLServer{}, stmt, `UPDATE [s_t] SET [a] = @p1,[b] = [s_t].[b] + @p2 FROM [s].[t] [s_t] WHERE [s_t].[id] = [u].[id]`)
+	stmt.FromClause = &FromClause{&FromClauseItem{
+		TableRef: &LabeledTable{Name: "u", Label: "u"},
+	}}
+	testCompileDialect(t, &SQLServer{}, stmt, `UPDATE [s_t] SET [a] = @p1,[b] = [s_t].[b] + @p2 FROM [s].[t] [s_t],[u] [u] WHERE [s_t].[id] = [u].[id]`)
+
+	c := NewCompiler(&Postgres{})
+	_, err := c.Compile(&UpdateStmt{Table: table})
+	testEqual(t, err, ErrZeroLength)
+
+	// A table without alias is referred to by its name.
+	unlabeled := &UpdateStmt{
+		Table:       &LabeledTable{Name: "t"},
+		Assignments: []*Assignment{Set("a", a)},
+		WhereClause: &WhereClause{Eq(&Column{"t", "id"}, b)},
+	}
+	testCompile(t, unlabeled, `UPDATE "t" SET "a" = $1 WHERE "t"."id" = $2`)
+	testCompileDialect(t, &SQLite{}, unlabeled, `UPDATE "t" SET "a" = ?1 WHERE "t"."id" = ?2`)
+	testCompileDialect(t, &SQLServer{}, unlabeled, `UPDATE [t] SET [a] = @p1 WHERE [t].[id] = @p2`)
+	unlabeled.FromClause = &FromClause{&FromClauseItem{
+		TableRef: &LabeledTable{Name: "u", Label: "u"},
+	}}
+	testCompileDialect(t, &SQLServer{}, unlabeled, `UPDATE [t] SET [a] = @p1 FROM [u] [u] WHERE [t].[id] = @p2`)
+}
+
+func TestDeleteStmt(t *testing.T) {
+	table := &LabeledTable{Schema: "s", Name: "t", Label: "s_t"}
+
+	stmt := &DeleteStmt{
+		Table: table,
+	}
+	testCompile(t, stmt, `DELETE FROM "s"."t" "s_t"`)
+
+	stmt.UsingClause = &UsingClause{&FromClauseItem{
+		TableRef: &LabeledTable{Name: "u", Label: "u"},
+	}}
+	testCompile(t, stmt, `DELETE FROM "s"."t" "s_t" USING "u" "u"`)
+
+	stmt.WhereClause = &WhereClause{Eq(&Column{"s_t", "id"}, &Column{"u", "id"})}
+	testCompile(t, stmt, `DELETE FROM "s"."t" "s_t" USING "u" "u" WHERE "s_t"."id" = "u"."id"`)
+
+	stmt.ReturningClause = &ReturningClause{[]*LabeledColumn{
+		&LabeledColumn{&Column{"s_t", "id"}, "T_ID"},
+	}}
+	testCompile(t, stmt, `DELETE FROM "s"."t" "s_t" USING "u" "u" WHERE "s_t"."id" = "u"."id" RETURNING "s_t"."id" "T_ID"`)
+
+	testUnsupported(t, &MySQL{}, stmt, "DELETE ... USING")
+	testUnsupported(t, &SQLite{}, stmt, "DELETE ... USING")
+	testUnsupported(t, &SQLServer{}, stmt, "RETURNING")
+	stmt.ReturningClause = nil
+	testCompileDialect(t, &SQLServer{}, stmt, `DELETE [s_t] FROM [s].[t] [s_t],[u] [u] WHERE [s_t].[id] = [u].[id]`)
+
+	stmt.UsingClause = nil
+	testCompileDialect(t, &SQLite{}, stmt, `DELETE FROM "s"."t" AS "s_t" WHERE "s_t"."id" = "u"."id"`)
+	testCompileDialect(t, &SQLServer{}, stmt, `DELETE [s_t] FROM [s].[t] [s_t] WHERE [s_t].[id] = [u].[id]`)
+
+	// A table without alias is referred to by its name.
+	unlabeled := &DeleteStmt{
+		Table:       &LabeledTable{Name: "t"},
+		WhereClause: &WhereClause{Eq(&Column{"t", "id"}, Placeholder("id"))},
+	}
+	testCompile(t, unlabeled, `DELETE FROM "t" WHERE "t"."id" = $1`)
+	testCompileDialect(t, &SQLite{}, unlabeled, `DELETE FROM "t" WHERE "t"."id" = ?1`)
+	testCompileDialect(t, &SQLServer{}, unlabeled, `DELETE FROM [t] WHERE [t].[id] = @p1`)
+	unlabeled.UsingClause = &UsingClause{&FromClauseItem{
+		TableRef: &LabeledTable{Name: "u", Label: "u"},
+	}}
+	testCompileDialect(t, &SQLServer{}, unlabeled, `DELETE [t] FROM [t],[u] [u] WHERE [t].[id] = @p1`)
+}
+
+func TestRequireWhere(t *testing.T) {
+	table := &LabeledTable{Name: "t", Label: "t"}
+	where := &WhereClause{Eq(&Column{"t", "id"}, Placeholder("id"))}
+
+	cases := []struct {
+		in  Node
+		err error
+	}{
+		{&DeleteStmt{Table: table}, ErrMissingWhereClause},
+		{&DeleteStmt{Table: table, WhereClause: where}, nil},
+		{&UpdateStmt{Table: table, Assignments: []*Assignment{Set("a", literal("1"))}}, ErrMissingWhereClause},
+		{&UpdateStmt{Table: table, Assignments: []*Assignment{Set("a", literal("1"))}, WhereClause: where}, nil},
+	}
+	for _, d := range []Dialect{&Postgres{}, &SQLServer{}} {
+		c := NewCompiler(d, WithRequireWhere())
+		for _, case_ := range cases {
+			_, err := c.Compile(case_.in)
+			testEqual(t, err, case_.err)
+		}
+	}
+}
+
+func TestUpsert(t *testing.T) {
+	stmt := &InsertStmt{
+		Table:   &Table{Name: "counter"},
+		Columns: []string{"id", "count"},
+		Values:  [][]Expr{{Placeholder("id"), Placeholder("count")}},
+	}
+
+	stmt.OnConflictClause = OnConflictDoNothing()
+	testCompile(t, stmt, `INSERT INTO "counter" ("id","count") VALUES ($1,$2) ON CONFLICT DO NOTHING`)
+	testCompileDialect(t, &SQLite{}, stmt, `INSERT INTO "counter" ("id","count") VALUES (?1,?2) ON CONFLICT DO NOTHING`)
+	testUnsupported(t, &MySQL{}, stmt, "ON CONFLICT DO NOTHING without conflict target")
+	testUnsupported(t, &SQLServer{}, stmt, "ON CONFLICT")
+
+	stmt.OnConflictClause = OnConflictDoNothing("id")
+	testCompile(t, stmt, `INSERT INTO "counter" ("id","count") VALUES ($1,$2) ON CONFLICT ("id") DO NOTHING`)
+	testCompileDialect(t, &MySQL{}, stmt, "INSERT INTO `counter` (`id`,`count`) VALUES (?,?) ON DUPLICATE KEY UPDATE `id` = `id`")
+
+	stmt.OnConflictClause = OnConflictDoUpdate(
+		[]string{"id"},
+		Set("count", Add(&Column{"counter", "count"}, Excluded("count"))),
+	)
+	testCompile(t, stmt, `INSERT INTO "counter" ("id","count") VALUES ($1,$2) ON CONFLICT ("id") DO UPDATE SET "count" = "counter"."count" + EXCLUDED."count"`)
+	testCompileDialect(t, &MySQL{}, stmt, "INSERT INTO `counter` (`id`,`count`) VALUES (?,?) ON DUPLICATE KEY UPDATE `count` = `counter`.`count` + VALUES(`count`)")
+
+	stmt.OnConflictClause.TargetWhereClause = &WhereClause{IsNotNull(&Column{"", "id"})}
+	stmt.OnConflictClause.WhereClause = &WhereClause{Lt(&Column{"counter", "count"}, Placeholder("max"))}
+	testCompile(t, stmt, `INSERT INTO "counter" ("id","count") VALUES ($1,$2) ON CONFLICT ("id") WHERE "id" IS NOT NULL DO UPDATE SET "count" = "counter"."count" + EXCLUDED."count" WHERE "counter"."count" < $3`)
+	testUnsupported(t, &MySQL{}, stmt, "ON CONFLICT ... WHERE")
+
+	stmt.OnConflictClause = &OnConflictClause{
+		Assignments: []*Assignment{Set("count", Excluded("count"))},
+	}
+	c := NewCompiler(&Postgres{})
+	_, err := c.Compile(stmt)
+	testEqual(t, err, ErrMissingConflictTarget)
+}
+
+func TestReturning(t *testing.T) {
+	type user struct {
+		ID   int
+		Name string
+	}
+	columns, err := LabeledColumns("User", "user", &user{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	insert := &InsertStmt{
+		Table:           &Table{Name: "user"},
+		Columns:         []string{"Name"},
+		Values:          [][]Expr{{Placeholder("name")}},
+		ReturningClause: &ReturningClause{columns},
+	}
+	testCompile(t, insert, `INSERT INTO "user" ("Name") VALUES ($1) RETURNING "user"."ID" "User_ID","user"."Name" "User_Name"`)
+
+	insert.OnConflictClause = OnConflictDoNothing("Name")
+	insert.ReturningClause = Returning(columns[0])
+	testCompile(t, insert, `INSERT INTO "user" ("Name") VALUES ($1) ON CONFLICT ("Name") DO NOTHING RETURNING "user"."ID" "User_ID"`)
+	testUnsupported(t, &MySQL{}, insert, "RETURNING")
+
+	// The columns of an aliased table are qualified with the alias.
+	aliased, err := LabeledColumns("User", "u", &user{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	update := &UpdateStmt{
+		Table:           &LabeledTable{Name: "user", Label: "u"},
+		Assignments:     []*Assignment{Set("Name", Placeholder("name"))},
+		ReturningClause: Returning(aliased[0], aliased[1:]...),
+	}
+	testCompile(t, update, `UPDATE "user" "u" SET "Name" = $1 RETURNING "u"."ID" "User_ID","u"."Name" "User_Name"`)
+}
+
+func TestLabeledColumns(t *testing.T) {
+	type user struct {
+		ID      int
+		Name    string
+		private bool
+	}
+	columns, err := LabeledColumns("Follower", "f", user{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	testEqual(t, len(columns), 2)
+	testCompile(t, columns[0], `"f"."ID" "Follower_ID"`)
+	testCompile(t, columns[1], `"f"."Name" "Follower_Name"`)
+
+	_, err = LabeledColumns("Follower", "f", 1)
+	testEqual(t, err, ErrOutputRowFieldMustBeStruct)
+	_, err = LabeledColumns("Follower", "f", struct{ a int }{})
+	testEqual(t, err, ErrZeroLength)
+}
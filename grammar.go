@@ -3,6 +3,7 @@ package flexsql
 import (
 	"errors"
 	"fmt"
+	"reflect"
 	"regexp"
 	"strconv"
 )
@@ -26,6 +27,46 @@ type Node interface {
 
 type Expr = Node
 
+// transformNode transforms n and reports whether the result
+// is a different node. Transform must never modify its receiver,
+// so a node whose children changed returns a modified copy instead.
+// If the Dialect is a Rewriter, the transformed node is rewritten as well.
+func transformNode(n Node, c *Compiler) (Node, bool) {
+	t := n.Transform(c)
+	if r, ok := c.dialect.(Rewriter); ok {
+		t = r.Rewrite(t)
+	}
+	return t, !sameNode(n, t)
+}
+
+func sameNode(a, b Node) bool {
+	typ := reflect.TypeOf(a)
+	if typ != reflect.TypeOf(b) || !typ.Comparable() {
+		return false
+	}
+	return a == b
+}
+
+// transformExprs transforms exprs. The input slice is never modified;
+// a new slice is allocated when any of the elements changed.
+func transformExprs(exprs []Expr, c *Compiler) ([]Expr, bool) {
+	var output []Expr
+	for i, e := range exprs {
+		t, changed := transformNode(e, c)
+		if changed && output == nil {
+			output = make([]Expr, len(exprs))
+			copy(output, exprs)
+		}
+		if output != nil {
+			output[i] = t
+		}
+	}
+	if output == nil {
+		return exprs, false
+	}
+	return output, true
+}
+
 type SQLType string
 
 func (s SQLType) Transform(c *Compiler) Node {
@@ -64,19 +105,33 @@ func Cast(expr Expr, sqlType SQLType) Expr {
 	}
 }
 
+func (ce *CastExpr) Expr() Expr {
+	return ce.expr
+}
+
+func (ce *CastExpr) Type() SQLType {
+	return ce.sqlType
+}
+
 func (ce *CastExpr) Transform(c *Compiler) Node {
-	ce.expr = ce.expr.Transform(c).(Expr)
-	ce.sqlType = ce.sqlType.Transform(c).(SQLType)
-	return ce
+	expr, exprChanged := transformNode(ce.expr, c)
+	sqlType, sqlTypeChanged := transformNode(ce.sqlType, c)
+	if !exprChanged && !sqlTypeChanged {
+		return ce
+	}
+	return &CastExpr{
+		expr:    expr,
+		sqlType: sqlType.(SQLType),
+	}
 }
 
 func (ce *CastExpr) Stringify(c *Compiler) error {
 	c.WriteVerbatim("CAST(")
-	if err := ce.expr.Stringify(c); err != nil {
+	if err := c.WriteNode(ce.expr); err != nil {
 		return err
 	}
 	c.WriteVerbatim(" AS ")
-	if err := ce.sqlType.Stringify(c); err != nil {
+	if err := c.WriteNode(ce.sqlType); err != nil {
 		return err
 	}
 	c.WriteVerbatim(")")
@@ -87,6 +142,9 @@ type FuncExpr struct {
 	name            string
 	args            []Expr
 	omitParentheses bool
+	filter          Expr
+	window          *WindowSpec
+	windowName      string
 }
 
 func checkFuncName(name string) {
@@ -114,33 +172,95 @@ func Func0(name string) *FuncExpr {
 	}
 }
 
+func (f *FuncExpr) Name() string {
+	return f.name
+}
+
+func (f *FuncExpr) Args() []Expr {
+	return f.args
+}
+
+// Filter returns a copy of the aggregate function f
+// only aggregating the rows for which cond holds.
+func (f *FuncExpr) Filter(cond Expr) *FuncExpr {
+	copied := *f
+	copied.filter = cond
+	return &copied
+}
+
+// Over returns a copy of f called as a window function over spec.
+func (f *FuncExpr) Over(spec *WindowSpec) *FuncExpr {
+	copied := *f
+	copied.window = spec
+	copied.windowName = ""
+	return &copied
+}
+
+// OverWindow returns a copy of f called as a window function
+// over the window of the WINDOW clause named name.
+func (f *FuncExpr) OverWindow(name string) *FuncExpr {
+	copied := *f
+	copied.window = nil
+	copied.windowName = name
+	return &copied
+}
+
 func (f *FuncExpr) Transform(c *Compiler) Node {
-	for i, v := range f.args {
-		f.args[i] = v.Transform(c).(Expr)
+	copied := *f
+	changed := false
+
+	if args, ok := transformExprs(f.args, c); ok {
+		copied.args = args
+		changed = true
 	}
-	return f
+	if f.filter != nil {
+		if n, ok := transformNode(f.filter, c); ok {
+			copied.filter = n
+			changed = true
+		}
+	}
+	if f.window != nil {
+		if n, ok := transformNode(f.window, c); ok {
+			copied.window = n.(*WindowSpec)
+			changed = true
+		}
+	}
+
+	if !changed {
+		return f
+	}
+	return &copied
 }
 
 func (f *FuncExpr) Stringify(c *Compiler) error {
 	c.WriteVerbatim(f.name)
-	if len(f.args) <= 0 {
-		if f.omitParentheses {
-			return nil
+	if len(f.args) > 0 {
+		c.WriteVerbatim("(")
+		if err := stringifyCommaSeparated(f.args, c); err != nil {
+			return err
 		}
+		c.WriteVerbatim(")")
+	} else if !f.omitParentheses {
 		c.WriteVerbatim("()")
-		return nil
 	}
-	c.WriteVerbatim("(")
-	if err := f.args[0].Stringify(c); err != nil {
-		return err
-	}
-	for _, e := range f.args[1:] {
-		c.WriteVerbatim(",")
-		if err := e.Stringify(c); err != nil {
+	if f.filter != nil {
+		if err := c.requireFeature(FeatureAggregateFilter); err != nil {
 			return err
 		}
+		c.WriteVerbatim(" FILTER (WHERE ")
+		if err := c.WriteNode(f.filter); err != nil {
+			return err
+		}
+		c.WriteVerbatim(")")
+	}
+	if f.window != nil {
+		c.WriteVerbatim(" OVER ")
+		return stringifyParen(f.window, c)
+	}
+	if f.windowName != "" {
+		c.WriteVerbatim(" OVER ")
+		c.WriteIdentifier(f.windowName)
 	}
-	c.WriteVerbatim(")")
 	return nil
 }
 
@@ -148,12 +268,12 @@ func stringifyCommaSeparated(nodes []Node, c *Compiler) error {
 	if len(nodes) <= 0 {
 		return nil
 	}
-	if err := nodes[0].Stringify(c); err != nil {
+	if err := c.WriteNode(nodes[0]); err != nil {
 		return err
 	}
 	for _, n := range nodes[1:] {
 		c.WriteVerbatim(",")
-		if err := n.Stringify(c); err != nil {
+		if err := c.WriteNode(n); err != nil {
 			return err
 		}
 	}
@@ -162,25 +282,50 @@ func stringifyCommaSeparated(nodes []Node, c *Compiler) error {
 
 func stringifyParen(node Node, c *Compiler) error {
 	c.WriteVerbatim("(")
-	if err := node.Stringify(c); err != nil {
+	if err := c.WriteNode(node); err != nil {
 		return err
 	}
 	c.WriteVerbatim(")")
 	return nil
 }
 
+// SubqueryExpr is a query in parentheses used as an expression,
+// e.g. a scalar subquery returning a single row and column.
+type SubqueryExpr struct {
+	Query Query
+}
+
+func Subquery(q Query) *SubqueryExpr {
+	return &SubqueryExpr{q}
+}
+
+func (s *SubqueryExpr) Transform(c *Compiler) Node {
+	query, changed := transformNode(s.Query, c)
+	if !changed {
+		return s
+	}
+	return &SubqueryExpr{query.(Query)}
+}
+
+func (s *SubqueryExpr) Stringify(c *Compiler) error {
+	return stringifyParen(s.Query, c)
+}
+
 type FromClause struct {
 	FromClauseItem *FromClauseItem
 }
 
 func (f *FromClause) Transform(c *Compiler) Node {
-	f.FromClauseItem = (f.FromClauseItem.Transform(c)).(*FromClauseItem)
-	return f
+	item, changed := transformNode(f.FromClauseItem, c)
+	if !changed {
+		return f
+	}
+	return &FromClause{item.(*FromClauseItem)}
 }
 
 func (f *FromClause) Stringify(c *Compiler) error {
 	c.WriteVerbatim("FROM ")
-	return f.FromClauseItem.Stringify(c)
+	return c.WriteNode(f.FromClauseItem)
 }
 
 type JoinClause struct {
@@ -190,23 +335,48 @@ type JoinClause struct {
 	on       Expr
 }
 
+// JoinType returns the join keyword, e.g. LEFT JOIN.
+func (j *JoinClause) JoinType() string {
+	return j.joinType
+}
+
+func (j *JoinClause) Left() *FromClauseItem {
+	return j.left
+}
+
+func (j *JoinClause) Right() *FromClauseItem {
+	return j.right
+}
+
+func (j *JoinClause) On() Expr {
+	return j.on
+}
+
 func (j *JoinClause) Transform(c *Compiler) Node {
-	j.left = (j.left.Transform(c)).(*FromClauseItem)
-	j.right = (j.right.Transform(c)).(*FromClauseItem)
-	j.on = (j.on.Transform(c)).(Expr)
-	return j
+	left, leftChanged := transformNode(j.left, c)
+	right, rightChanged := transformNode(j.right, c)
+	on, onChanged := transformNode(j.on, c)
+	if !leftChanged && !rightChanged && !onChanged {
+		return j
+	}
+	return &JoinClause{
+		joinType: j.joinType,
+		left:     left.(*FromClauseItem),
+		right:    right.(*FromClauseItem),
+		on:       on,
+	}
 }
 
 func (j *JoinClause) Stringify(c *Compiler) error {
-	if err := j.left.Stringify(c); err != nil {
+	if err := c.WriteNode(j.left); err != nil {
 		return err
 	}
 	c.WriteVerbatim(" " + j.joinType + " ")
-	if err := j.right.Stringify(c); err != nil {
+	if err := c.WriteNode(j.right); err != nil {
 		return err
 	}
 	c.WriteVerbatim(" ON ")
-	return j.on.Stringify(c)
+	return c.WriteNode(j.on)
 }
 
 func Join(left, right *FromClauseItem, on Expr) *JoinClause {
@@ -251,13 +421,19 @@ type LabeledSelectStmt struct {
 }
 
 func (l *LabeledSelectStmt) Transform(c *Compiler) Node {
-	l.SelectStmt = (l.SelectStmt.Transform(c)).(*SelectStmt)
-	return l
+	selectStmt, changed := transformNode(l.SelectStmt, c)
+	if !changed {
+		return l
+	}
+	return &LabeledSelectStmt{
+		SelectStmt: selectStmt.(*SelectStmt),
+		Label:      l.Label,
+	}
 }
 
 func (l *LabeledSelectStmt) Stringify(c *Compiler) error {
 	c.WriteVerbatim("(")
-	if err := l.SelectStmt.Stringify(c); err != nil {
+	if err := c.WriteNode(l.SelectStmt); err != nil {
 		return err
 	}
 	c.WriteVerbatim(") ")
@@ -289,11 +465,18 @@ type LabeledColumn struct {
 }
 
 func (l *LabeledColumn) Transform(c *Compiler) Node {
-	return l
+	expr, changed := transformNode(l.Expr, c)
+	if !changed {
+		return l
+	}
+	return &LabeledColumn{
+		Expr:  expr,
+		Label: l.Label,
+	}
 }
 
 func (l *LabeledColumn) Stringify(c *Compiler) error {
-	if err := l.Expr.Stringify(c); err != nil {
+	if err := c.WriteNode(l.Expr); err != nil {
 		return err
 	}
 	c.WriteVerbatim(" ")
@@ -319,6 +502,8 @@ func (t *Table) Stringify(c *Compiler) error {
 	return nil
 }
 
+// LabeledTable is a table with an alias.
+// Label may be empty to refer to the table by its name.
 type LabeledTable struct {
 	Schema string
 	Name   string
@@ -335,35 +520,50 @@ func (l *LabeledTable) Stringify(c *Compiler) error {
 		c.WriteVerbatim(".")
 	}
 	c.WriteIdentifier(l.Name)
-	c.WriteVerbatim(" ")
-	c.WriteIdentifier(l.Label)
+	if l.Label != "" {
+		c.WriteVerbatim(" ")
+		c.WriteIdentifier(l.Label)
+	}
 	return nil
 }
 
 type FromClauseItem struct {
-	TableRef   *LabeledTable
-	Subquery   *LabeledSelectStmt
-	JoinClause *JoinClause
+	TableRef     *LabeledTable
+	Subquery     *LabeledSelectStmt
+	JoinClause   *JoinClause
+	SetOperation *LabeledSetOperation
 }
 
 func (f *FromClauseItem) Transform(c *Compiler) Node {
 	if f.TableRef != nil {
-		f.TableRef = (f.TableRef.Transform(c)).(*LabeledTable)
+		if n, changed := transformNode(f.TableRef, c); changed {
+			return &FromClauseItem{TableRef: n.(*LabeledTable)}
+		}
 	} else if f.Subquery != nil {
-		f.Subquery = (f.Subquery.Transform(c)).(*LabeledSelectStmt)
+		if n, changed := transformNode(f.Subquery, c); changed {
+			return &FromClauseItem{Subquery: n.(*LabeledSelectStmt)}
+		}
 	} else if f.JoinClause != nil {
-		f.JoinClause = (f.JoinClause.Transform(c)).(*JoinClause)
+		if n, changed := transformNode(f.JoinClause, c); changed {
+			return &FromClauseItem{JoinClause: n.(*JoinClause)}
+		}
+	} else if f.SetOperation != nil {
+		if n, changed := transformNode(f.SetOperation, c); changed {
+			return &FromClauseItem{SetOperation: n.(*LabeledSetOperation)}
+		}
 	}
 	return f
 }
 
 func (f *FromClauseItem) Stringify(c *Compiler) error {
 	if f.TableRef != nil {
-		return f.TableRef.Stringify(c)
+		return c.WriteNode(f.TableRef)
 	} else if f.Subquery != nil {
-		return f.Subquery.Stringify(c)
+		return c.WriteNode(f.Subquery)
 	} else if f.JoinClause != nil {
-		return f.JoinClause.Stringify(c)
+		return c.WriteNode(f.JoinClause)
+	} else if f.SetOperation != nil {
+		return c.WriteNode(f.SetOperation)
 	}
 	return ErrUnknownFromClauseItem
 }
@@ -373,10 +573,11 @@ type Tuple struct {
 }
 
 func (t *Tuple) Transform(c *Compiler) Node {
-	for i, e := range t.exprs {
-		t.exprs[i] = e.Transform(c).(Expr)
+	exprs, changed := transformExprs(t.exprs, c)
+	if !changed {
+		return t
 	}
-	return t
+	return &Tuple{exprs}
 }
 
 func (t *Tuple) Stringify(c *Compiler) error {
@@ -422,33 +623,37 @@ func (ce *CaseExpr) Else(elseExpr Expr) *CaseExpr {
 }
 
 func (ce *CaseExpr) Transform(c *Compiler) Node {
-	for i, v := range ce.conds {
-		ce.conds[i] = v.Transform(c).(Expr)
+	conds, condsChanged := transformExprs(ce.conds, c)
+	results, resultsChanged := transformExprs(ce.results, c)
+	else_, elseChanged := ce.else_, false
+	if ce.else_ != nil {
+		else_, elseChanged = transformNode(ce.else_, c)
 	}
-	for i, v := range ce.results {
-		ce.results[i] = v.Transform(c).(Expr)
+	if !condsChanged && !resultsChanged && !elseChanged {
+		return ce
 	}
-	if ce.else_ != nil {
-		ce.else_ = ce.else_.Transform(c).(Expr)
+	return &CaseExpr{
+		conds:   conds,
+		results: results,
+		else_:   else_,
 	}
-	return ce
 }
 
 func (ce *CaseExpr) Stringify(c *Compiler) error {
 	c.WriteVerbatim("CASE")
 	for i := 0; i < len(ce.conds); i++ {
 		c.WriteVerbatim(" WHEN ")
-		if err := ce.conds[i].Stringify(c); err != nil {
+		if err := c.WriteNode(ce.conds[i]); err != nil {
 			return err
 		}
 		c.WriteVerbatim(" THEN ")
-		if err := ce.results[i].Stringify(c); err != nil {
+		if err := c.WriteNode(ce.results[i]); err != nil {
 			return err
 		}
 	}
 	if ce.else_ != nil {
 		c.WriteVerbatim(" ELSE ")
-		if err := ce.else_.Stringify(c); err != nil {
+		if err := c.WriteNode(ce.else_); err != nil {
 			return err
 		}
 	}
@@ -464,7 +669,10 @@ func (p Placeholder) Transform(c *Compiler) Node {
 
 func (p Placeholder) Stringify(c *Compiler) error {
 	pos := c.insertPlaceholder(string(p))
-	rendered := c.makePlaceholder(string(p), pos)
+	rendered, err := c.makePlaceholder(string(p), pos)
+	if err != nil {
+		return err
+	}
 	c.WriteVerbatim(rendered)
 	return nil
 }
@@ -503,18 +711,36 @@ func PlaceholderTuple(prefix string, length int) ([]Placeholder, Node, error) {
 	return placeholders, tuple, err
 }
 
+// Placeholders returns length placeholders named like those of
+// PlaceholderTuple, both as they are and as expressions to be passed to
+// InValues. Unlike PlaceholderTuple, length may be zero.
+func Placeholders(prefix string, length int) ([]Placeholder, []Expr) {
+	if length <= 0 {
+		return nil, nil
+	}
+	placeholders, _ := generatePlaceholders(prefix, length)
+	exprs := make([]Expr, length)
+	for i, v := range placeholders {
+		exprs[i] = v
+	}
+	return placeholders, exprs
+}
+
 type WhereClause struct {
 	Expr Expr
 }
 
 func (w *WhereClause) Transform(c *Compiler) Node {
-	w.Expr = w.Expr.Transform(c).(Expr)
-	return w
+	expr, changed := transformNode(w.Expr, c)
+	if !changed {
+		return w
+	}
+	return &WhereClause{expr}
 }
 
 func (w *WhereClause) Stringify(c *Compiler) error {
 	c.WriteVerbatim("WHERE ")
-	return w.Expr.Stringify(c)
+	return c.WriteNode(w.Expr)
 }
 
 type GroupByClause struct {
@@ -522,10 +748,11 @@ type GroupByClause struct {
 }
 
 func (g *GroupByClause) Transform(c *Compiler) Node {
-	for i, e := range g.exprs {
-		g.exprs[i] = e.Transform(c).(Expr)
+	exprs, changed := transformExprs(g.exprs, c)
+	if !changed {
+		return g
 	}
-	return g
+	return &GroupByClause{exprs}
 }
 
 func (g *GroupByClause) Stringify(c *Compiler) error {
@@ -547,13 +774,16 @@ type HavingClause struct {
 }
 
 func (h *HavingClause) Transform(c *Compiler) Node {
-	h.Expr = (h.Expr.Transform(c)).(Expr)
-	return h
+	expr, changed := transformNode(h.Expr, c)
+	if !changed {
+		return h
+	}
+	return &HavingClause{expr}
 }
 
 func (h *HavingClause) Stringify(c *Compiler) error {
 	c.WriteVerbatim("HAVING ")
-	return h.Expr.Stringify(c)
+	return c.WriteNode(h.Expr)
 }
 
 type OrderByItem interface {
@@ -592,22 +822,31 @@ func (o *orderbyItem) private() {
 }
 
 func (o *orderbyItem) Transform(c *Compiler) Node {
-	o.expr = o.expr.Transform(c).(Expr)
-	return o
+	expr, changed := transformNode(o.expr, c)
+	if !changed {
+		return o
+	}
+	copied := *o
+	copied.expr = expr
+	return &copied
 }
 
 func (o *orderbyItem) Stringify(c *Compiler) error {
-	if err := o.expr.Stringify(c); err != nil {
+	if o.nullsSet {
+		if err := c.requireFeature(FeatureNullsOrdering); err != nil {
+			return err
+		}
+	}
+	if err := c.WriteNode(o.expr); err != nil {
 		return err
 	}
 	if o.desc {
 		c.WriteVerbatim(" DESC")
 	}
 	if o.nullsSet {
-		if o.desc && o.nullsLast {
+		if o.nullsLast {
 			c.WriteVerbatim(" NULLS LAST")
-		}
-		if !o.desc && !o.nullsLast {
+		} else {
 			c.WriteVerbatim(" NULLS FIRST")
 		}
 	}
@@ -649,10 +888,74 @@ type OrderByClause struct {
 }
 
 func (o *OrderByClause) Transform(c *Compiler) Node {
+	outputOrderBy := c.outputOrderBy
+	c.outputOrderBy = false
+	var items []OrderByItem
 	for i, e := range o.items {
-		o.items[i] = e.Transform(c).(OrderByItem)
+		t, changed := transformNode(e, c)
+		if changed && items == nil {
+			items = make([]OrderByItem, len(o.items))
+			copy(items, o.items)
+		}
+		if items != nil {
+			items[i] = t.(OrderByItem)
+		}
+	}
+	if e, ok := c.dialect.(NullsOrderingEmulator); ok && !c.supports(FeatureNullsOrdering) {
+		source := items
+		if source == nil {
+			source = o.items
+		}
+		if emulated, changed := emulateNullsOrdering(source, e, outputOrderBy); changed {
+			items = emulated
+		}
+	}
+	if items == nil {
+		return o
+	}
+	return &OrderByClause{items}
+}
+
+// emulateNullsOrdering removes NULLS FIRST and NULLS LAST from items.
+// An item whose requested order of NULLs differs from the default
+// of the Dialect is preceded by the sort key of its expression.
+// If outputOrderBy is set, the sort key cannot be added, so such an item
+// is kept as is and rejected when it is written.
+func emulateNullsOrdering(items []OrderByItem, e NullsOrderingEmulator, outputOrderBy bool) ([]OrderByItem, bool) {
+	changed := false
+	output := make([]OrderByItem, 0, len(items))
+	for _, item := range items {
+		if !item.HasNullsSet() {
+			output = append(output, item)
+			continue
+		}
+		changed = true
+		plain := Asc(item.Expr())
+		if item.IsDesc() {
+			plain = Desc(item.Expr())
+		}
+		nullsFirstByDefault := e.NullsFirstByDefault() != item.IsDesc()
+		if item.IsNullsLast() != nullsFirstByDefault {
+			output = append(output, plain)
+			continue
+		}
+		if outputOrderBy {
+			output = append(output, item)
+			continue
+		}
+		if item.IsNullsLast() {
+			output = append(output, Asc(e.NullsSortKey(item.Expr())), plain)
+		} else {
+			output = append(output, Desc(e.NullsSortKey(item.Expr())), plain)
+		}
 	}
-	return o
+	return output, changed
+}
+
+// caseNullsSortKey returns CASE WHEN expr IS NULL THEN 1 ELSE 0 END,
+// a NullsSortKey for dialects without boolean sort keys.
+func caseNullsSortKey(expr Expr) Expr {
+	return Case(IsNull(expr), IntLiteral(1)).Else(IntLiteral(0))
 }
 
 func (o *OrderByClause) Stringify(c *Compiler) error {
@@ -678,13 +981,16 @@ type LimitClause struct {
 }
 
 func (l *LimitClause) Transform(c *Compiler) Node {
-	l.Expr = (l.Expr.Transform(c)).(Expr)
-	return l
+	expr, changed := transformNode(l.Expr, c)
+	if !changed {
+		return l
+	}
+	return &LimitClause{expr}
 }
 
 func (l *LimitClause) Stringify(c *Compiler) error {
 	c.WriteVerbatim("LIMIT ")
-	return l.Expr.Stringify(c)
+	return c.WriteNode(l.Expr)
 }
 
 type OffsetClause struct {
@@ -692,104 +998,206 @@ type OffsetClause struct {
 }
 
 func (o *OffsetClause) Transform(c *Compiler) Node {
-	o.Expr = (o.Expr.Transform(c)).(Expr)
-	return o
+	expr, changed := transformNode(o.Expr, c)
+	if !changed {
+		return o
+	}
+	return &OffsetClause{expr}
 }
 
 func (o *OffsetClause) Stringify(c *Compiler) error {
 	c.WriteVerbatim("OFFSET ")
-	return o.Expr.Stringify(c)
+	return c.WriteNode(o.Expr)
 }
 
+// SelectStmt is a SELECT statement.
+// DistinctOn implies Distinct.
 type SelectStmt struct {
+	WithClause    *WithClause
+	Distinct      bool
+	DistinctOn    []Expr
 	Columns       []*LabeledColumn
 	FromClause    *FromClause
 	WhereClause   *WhereClause
 	GroupByClause *GroupByClause
 	HavingClause  *HavingClause
+	WindowClause  *WindowClause
 	OrderByClause *OrderByClause
 	LimitClause   *LimitClause
 	OffsetClause  *OffsetClause
 }
 
+func (s *SelectStmt) private() {
+}
+
 func (s *SelectStmt) Transform(c *Compiler) Node {
+	copied := *s
+	changed := false
+
+	if s.WithClause != nil {
+		if n, ok := transformNode(s.WithClause, c); ok {
+			copied.WithClause = n.(*WithClause)
+			changed = true
+		}
+	}
+
+	if distinctOn, ok := transformExprs(s.DistinctOn, c); ok {
+		copied.DistinctOn = distinctOn
+		changed = true
+	}
+	var columns []*LabeledColumn
 	for i, v := range s.Columns {
-		s.Columns[i] = (v.Transform(c)).(*LabeledColumn)
+		t, columnChanged := transformNode(v, c)
+		if columnChanged && columns == nil {
+			columns = make([]*LabeledColumn, len(s.Columns))
+			copy(columns, s.Columns)
+		}
+		if columns != nil {
+			columns[i] = t.(*LabeledColumn)
+		}
+	}
+	if columns != nil {
+		copied.Columns = columns
+		changed = true
 	}
 	if s.FromClause != nil {
-		s.FromClause = (s.FromClause.Transform(c)).(*FromClause)
+		if n, ok := transformNode(s.FromClause, c); ok {
+			copied.FromClause = n.(*FromClause)
+			changed = true
+		}
 	}
 	if s.WhereClause != nil {
-		s.WhereClause = (s.WhereClause.Transform(c)).(*WhereClause)
+		if n, ok := transformNode(s.WhereClause, c); ok {
+			copied.WhereClause = n.(*WhereClause)
+			changed = true
+		}
 	}
 	if s.GroupByClause != nil {
-		s.GroupByClause = (s.GroupByClause.Transform(c)).(*GroupByClause)
+		if n, ok := transformNode(s.GroupByClause, c); ok {
+			copied.GroupByClause = n.(*GroupByClause)
+			changed = true
+		}
 	}
 	if s.HavingClause != nil {
-		s.HavingClause = (s.HavingClause.Transform(c)).(*HavingClause)
+		if n, ok := transformNode(s.HavingClause, c); ok {
+			copied.HavingClause = n.(*HavingClause)
+			changed = true
+		}
+	}
+	if s.WindowClause != nil {
+		if n, ok := transformNode(s.WindowClause, c); ok {
+			copied.WindowClause = n.(*WindowClause)
+			changed = true
+		}
 	}
 	if s.OrderByClause != nil {
-		s.OrderByClause = (s.OrderByClause.Transform(c)).(*OrderByClause)
+		c.outputOrderBy = s.Distinct || len(s.DistinctOn) > 0
+		if n, ok := transformNode(s.OrderByClause, c); ok {
+			copied.OrderByClause = n.(*OrderByClause)
+			changed = true
+		}
 	}
 	if s.LimitClause != nil {
-		s.LimitClause = (s.LimitClause.Transform(c)).(*LimitClause)
+		if n, ok := transformNode(s.LimitClause, c); ok {
+			copied.LimitClause = n.(*LimitClause)
+			changed = true
+		}
 	}
 	if s.OffsetClause != nil {
-		s.OffsetClause = (s.OffsetClause.Transform(c)).(*OffsetClause)
+		if n, ok := transformNode(s.OffsetClause, c); ok {
+			copied.OffsetClause = n.(*OffsetClause)
+			changed = true
+		}
 	}
-	return s
+
+	if !changed {
+		return s
+	}
+	return &copied
 }
 
 func (s *SelectStmt) Stringify(c *Compiler) error {
+	if err := stringifyWithClause(s.WithClause, c); err != nil {
+		return err
+	}
 	c.WriteVerbatim("SELECT ")
-	if err := s.Columns[0].Stringify(c); err != nil {
+	if len(s.DistinctOn) > 0 {
+		if err := c.requireFeature(FeatureDistinctOn); err != nil {
+			return err
+		}
+		c.WriteVerbatim("DISTINCT ON (")
+		if err := stringifyCommaSeparated(s.DistinctOn, c); err != nil {
+			return err
+		}
+		c.WriteVerbatim(") ")
+	} else if s.Distinct {
+		c.WriteVerbatim("DISTINCT ")
+	}
+	if err := c.WriteNode(s.Columns[0]); err != nil {
 		return err
 	}
 	for _, se := range s.Columns[1:] {
 		c.WriteVerbatim(",")
-		if err := se.Stringify(c); err != nil {
+		if err := c.WriteNode(se); err != nil {
 			return err
 		}
 	}
 	if s.FromClause != nil {
-		c.WriteVerbatim(" ")
-		if err := s.FromClause.Stringify(c); err != nil {
+		c.writeClauseSeparator()
+		if err := c.WriteNode(s.FromClause); err != nil {
 			return err
 		}
 	}
 	if s.WhereClause != nil {
-		c.WriteVerbatim(" ")
-		if err := s.WhereClause.Stringify(c); err != nil {
+		c.writeClauseSeparator()
+		if err := c.WriteNode(s.WhereClause); err != nil {
 			return err
 		}
 	}
 	if s.GroupByClause != nil {
-		c.WriteVerbatim(" ")
-		if err := s.GroupByClause.Stringify(c); err != nil {
+		c.writeClauseSeparator()
+		if err := c.WriteNode(s.GroupByClause); err != nil {
 			return err
 		}
 	}
 	if s.HavingClause != nil {
-		c.WriteVerbatim(" ")
-		if err := s.HavingClause.Stringify(c); err != nil {
+		c.writeClauseSeparator()
+		if err := c.WriteNode(s.HavingClause); err != nil {
+			return err
+		}
+	}
+	if s.WindowClause != nil {
+		c.writeClauseSeparator()
+		if err := c.WriteNode(s.WindowClause); err != nil {
 			return err
 		}
 	}
 	if s.OrderByClause != nil {
-		c.WriteVerbatim(" ")
-		if err := s.OrderByClause.Stringify(c); err != nil {
+		c.writeClauseSeparator()
+		if err := c.WriteNode(s.OrderByClause); err != nil {
 			return err
 		}
 	}
-	if s.LimitClause != nil {
-		c.WriteVerbatim(" ")
-		if err := s.LimitClause.Stringify(c); err != nil {
+	return stringifyRowLimit(c, s.OrderByClause, s.LimitClause, s.OffsetClause)
+}
+
+func stringifyRowLimit(c *Compiler, orderBy *OrderByClause, limit *LimitClause, offset *OffsetClause) error {
+	if limit == nil && offset == nil {
+		return nil
+	}
+	if r, ok := c.dialect.(RowLimitRenderer); ok {
+		c.writeClauseSeparator()
+		return r.RenderRowLimit(c, orderBy, limit, offset)
+	}
+	if limit != nil {
+		c.writeClauseSeparator()
+		if err := c.WriteNode(limit); err != nil {
 			return err
 		}
 	}
-	if s.OffsetClause != nil {
-		c.WriteVerbatim(" ")
-		if err := s.OffsetClause.Stringify(c); err != nil {
+	if offset != nil {
+		c.writeClauseSeparator()
+		if err := c.WriteNode(offset); err != nil {
 			return err
 		}
 	}
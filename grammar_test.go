@@ -57,6 +57,17 @@ func TestSubquery(t *testing.T) {
 	testCompile(t, s, `(SELECT 1 "one") "s"`)
 }
 
+func TestSubqueryExpr(t *testing.T) {
+	a := literal("a")
+	q := &SelectStmt{Columns: []*LabeledColumn{{Func("max")(a), "m"}}}
+	cases := []compileTest{
+		{Subquery(q), `(SELECT max(a) "m")`},
+		{Eq(a, Subquery(q)), `a = (SELECT max(a) "m")`},
+		{Add(Subquery(Union(q, q)), a), `(SELECT max(a) "m" UNION SELECT max(a) "m") + a`},
+	}
+	testMany(t, cases)
+}
+
 func TestJoin(t *testing.T) {
 	t1 := &LabeledTable{
 		Schema: "s",
@@ -178,6 +189,27 @@ func TestSelectStmt(t *testing.T) {
 	testCompile(t, sel, `SELECT 1 "a" FROM "s"."a" "s_a" WHERE TRUE GROUP BY f HAVING FALSE ORDER BY f LIMIT 10 OFFSET 20`)
 }
 
+func TestSelectDistinct(t *testing.T) {
+	f := &Column{"", "f"}
+	sel := &SelectStmt{
+		Distinct: true,
+		Columns:  []*LabeledColumn{{f, "a"}},
+	}
+	testCompile(t, sel, `SELECT DISTINCT "f" "a"`)
+	testCompileDialect(t, &MySQL{}, sel, "SELECT DISTINCT `f` `a`")
+
+	sel = &SelectStmt{
+		DistinctOn:    []Expr{f, Not(Not(literal("g")))},
+		Columns:       []*LabeledColumn{{f, "a"}},
+		OrderByClause: OrderBy(Asc(f), Desc(literal("g"))),
+	}
+	testCompile(t, sel, `SELECT DISTINCT ON ("f",g) "f" "a" ORDER BY "f",g DESC`)
+	testDeepEqual(t, sel.DistinctOn[1], Not(Not(literal("g"))))
+	testUnsupported(t, &MySQL{}, sel, "DISTINCT ON")
+	testUnsupported(t, &SQLite{}, sel, "DISTINCT ON")
+	testUnsupported(t, &SQLServer{}, sel, "DISTINCT ON")
+}
+
 func TestTuple(t *testing.T) {
 	f := literal("f")
 	cases := []compileTest{
@@ -187,6 +219,16 @@ func TestTuple(t *testing.T) {
 	testMany(t, cases)
 }
 
+func TestPlaceholdersExprs(t *testing.T) {
+	placeholders, exprs := Placeholders("a", 2)
+	testDeepEqual(t, placeholders, []Placeholder{"a1", "a2"})
+	testDeepEqual(t, exprs, []Expr{Placeholder("a1"), Placeholder("a2")})
+
+	placeholders, exprs = Placeholders("a", 0)
+	testEqual(t, len(placeholders), 0)
+	testEqual(t, len(exprs), 0)
+}
+
 func TestPlaceholders(t *testing.T) {
 	cases := []struct {
 		prefix       string
@@ -202,9 +244,7 @@ func TestPlaceholders(t *testing.T) {
 		},
 	}
 	for _, case_ := range cases {
-		c := &Compiler{
-			dialect: &Postgres{},
-		}
+		c := NewCompiler(&Postgres{})
 		placeholders, tuple, err := PlaceholderTuple(case_.prefix, case_.length)
 		if err != nil {
 			t.Errorf("unexpected error: %v", err)
@@ -212,8 +252,9 @@ func TestPlaceholders(t *testing.T) {
 		actual, err := c.Compile(tuple)
 		if err != nil {
 			t.Errorf("unexpected error: %v", err)
+			continue
 		}
-		testEqual(t, actual, case_.out)
+		testEqual(t, actual.SQL(), case_.out)
 		if len(placeholders) != len(case_.placeholders) {
 			t.Errorf("unmatched length")
 		}
@@ -248,11 +289,22 @@ func TestOrderByItem(t *testing.T) {
 		{Asc(f), "f"},
 		{Desc(f), "f DESC"},
 		{NullsFirst(Asc(f)), "f NULLS FIRST"},
-		{NullsLast(Asc(f)), "f"},
-		{NullsFirst(Desc(f)), "f DESC"},
+		{NullsLast(Asc(f)), "f NULLS LAST"},
+		{NullsFirst(Desc(f)), "f DESC NULLS FIRST"},
 		{NullsLast(Desc(f)), "f DESC NULLS LAST"},
 	}
 	testMany(t, cases)
+	testUnsupported(t, &noNullsOrderingDialect{}, OrderBy(NullsLast(Asc(f))), "NULLS FIRST/LAST")
+	testCompileDialect(t, &noNullsOrderingDialect{}, OrderBy(Asc(f)), "ORDER BY f")
+}
+
+// noNullsOrderingDialect neither supports nor emulates NULLS FIRST/LAST.
+type noNullsOrderingDialect struct {
+	Postgres
+}
+
+func (d *noNullsOrderingDialect) Supports(f Feature) bool {
+	return f != FeatureNullsOrdering
 }
 
 func TestOrderByClause(t *testing.T) {
@@ -302,3 +354,57 @@ func TestCast(t *testing.T) {
 	}
 	testMany(t, cases)
 }
+
+func TestTransformDoesNotMutate(t *testing.T) {
+	a := literal("a")
+	where := &WhereClause{Not(Not(Eq(a, a)))}
+	cast := Cast(Not(Not(a)), Smallint)
+	sel := &SelectStmt{
+		Columns: []*LabeledColumn{
+			&LabeledColumn{cast, "c"},
+		},
+		WhereClause:   where,
+		OrderByClause: OrderBy(Asc(Not(Not(a)))),
+	}
+	expected := `SELECT CAST(a AS SMALLINT) "c" WHERE a = a ORDER BY a`
+
+	testCompile(t, sel, expected)
+	testCompile(t, sel, expected)
+
+	if sel.WhereClause != where {
+		t.Errorf("WhereClause was replaced")
+	}
+	if _, ok := where.Expr.(*UnaryOperator); !ok {
+		t.Errorf("WhereClause was mutated")
+	}
+	if sel.Columns[0].Expr != cast {
+		t.Errorf("LabeledColumn was mutated")
+	}
+	if _, ok := cast.(*CastExpr).expr.(*UnaryOperator); !ok {
+		t.Errorf("CastExpr was mutated")
+	}
+
+	// Sharing a sub-expression between two trees is safe.
+	shared := Not(Not(a))
+	testCompile(t, And(shared, Or(shared, a)), "a AND (a OR a)")
+	testCompile(t, shared, "a")
+	if _, ok := shared.Expr.(*UnaryOperator); !ok {
+		t.Errorf("shared sub-expression was mutated")
+	}
+}
+
+func TestCaseExprBuilder(t *testing.T) {
+	a := literal("a")
+	b := literal("b")
+	ce := Case(a, b)
+	ce.When(b, a)
+	ce.Else(a)
+	testCompile(t, ce, "CASE WHEN a THEN b WHEN b THEN a ELSE a END")
+
+	// Compiling does not modify the builder.
+	shared := Case(Not(Not(a)), b)
+	testCompile(t, shared, "CASE WHEN a THEN b END")
+	if _, ok := shared.conds[0].(*UnaryOperator); !ok {
+		t.Errorf("CaseExpr was mutated by Compile")
+	}
+}
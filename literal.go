@@ -0,0 +1,130 @@
+package flexsql
+
+import (
+	"encoding/hex"
+	"errors"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	ErrNonFiniteFloat = errors.New("Float literal must be finite")
+)
+
+// The literals below are rendered in standard SQL.
+// Dialects deviating from it render them with NodeRenderer.
+
+// StringLiteral is a character string constant.
+type StringLiteral string
+
+func (s StringLiteral) Transform(c *Compiler) Node {
+	return s
+}
+
+func (s StringLiteral) Stringify(c *Compiler) error {
+	c.WriteVerbatim(quoteString(string(s)))
+	return nil
+}
+
+// quoteString quotes s with single quotes, doubling the quotes in s.
+func quoteString(s string) string {
+	return "'" + strings.Replace(s, "'", "''", -1) + "'"
+}
+
+type IntLiteral int64
+
+func (i IntLiteral) Transform(c *Compiler) Node {
+	return i
+}
+
+func (i IntLiteral) Stringify(c *Compiler) error {
+	c.WriteVerbatim(strconv.FormatInt(int64(i), 10))
+	return nil
+}
+
+// FloatLiteral is a numeric constant with a fractional part or
+// an exponent. NaN and infinities are rejected by Compile.
+type FloatLiteral float64
+
+func (f FloatLiteral) Transform(c *Compiler) Node {
+	return f
+}
+
+func (f FloatLiteral) Stringify(c *Compiler) error {
+	v := float64(f)
+	if math.IsNaN(v) || math.IsInf(v, 0) {
+		return ErrNonFiniteFloat
+	}
+	s := strconv.FormatFloat(v, 'g', -1, 64)
+	if !strings.ContainsAny(s, ".e") {
+		s += ".0"
+	}
+	c.WriteVerbatim(s)
+	return nil
+}
+
+type BoolLiteral bool
+
+func (b BoolLiteral) Transform(c *Compiler) Node {
+	return b
+}
+
+func (b BoolLiteral) Stringify(c *Compiler) error {
+	if b {
+		c.WriteVerbatim("TRUE")
+	} else {
+		c.WriteVerbatim("FALSE")
+	}
+	return nil
+}
+
+type NullLiteral struct{}
+
+// Null is the NULL constant.
+var Null = NullLiteral{}
+
+func (n NullLiteral) Transform(c *Compiler) Node {
+	return n
+}
+
+func (n NullLiteral) Stringify(c *Compiler) error {
+	c.WriteVerbatim("NULL")
+	return nil
+}
+
+// BytesLiteral is a binary string constant,
+// rendered as X'0102' in standard SQL.
+// It is a struct used by pointer, unlike the other literals,
+// so that it is comparable.
+type BytesLiteral struct {
+	Bytes []byte
+}
+
+func (b *BytesLiteral) Transform(c *Compiler) Node {
+	return b
+}
+
+func (b *BytesLiteral) Stringify(c *Compiler) error {
+	c.WriteVerbatim("X'")
+	c.WriteVerbatim(hex.EncodeToString(b.Bytes))
+	c.WriteVerbatim("'")
+	return nil
+}
+
+// TimeLiteral is a timestamp constant without time zone,
+// written in the location of the time with microsecond precision.
+type TimeLiteral time.Time
+
+const timeLiteralLayout = "2006-01-02 15:04:05.999999"
+
+func (t TimeLiteral) Transform(c *Compiler) Node {
+	return t
+}
+
+func (t TimeLiteral) Stringify(c *Compiler) error {
+	c.WriteVerbatim("TIMESTAMP ")
+	c.WriteVerbatim(quoteString(time.Time(t).Format(timeLiteralLayout)))
+	return nil
+}
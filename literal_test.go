@@ -0,0 +1,75 @@
+package flexsql
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestLiteral(t *testing.T) {
+	ts := TimeLiteral(time.Date(2019, 3, 4, 5, 6, 7, 890000000, time.UTC))
+	cases := []compileTest{
+		{StringLiteral(""), `''`},
+		{StringLiteral("it's"), `'it''s'`},
+		{StringLiteral(`a\b`), `E'a\\b'`},
+		{StringLiteral(`'\'`), `E'''\\'''`},
+		{IntLiteral(42), `42`},
+		{IntLiteral(-42), `-42`},
+		{FloatLiteral(1.5), `1.5`},
+		{FloatLiteral(2), `2.0`},
+		{FloatLiteral(1e21), `1e+21`},
+		{BoolLiteral(true), `TRUE`},
+		{BoolLiteral(false), `FALSE`},
+		{Null, `NULL`},
+		{&BytesLiteral{[]byte{0xde, 0xad}}, `E'\\xdead'::bytea`},
+		{&BytesLiteral{[]byte{}}, `E'\\x'::bytea`},
+		{ts, `TIMESTAMP '2019-03-04 05:06:07.89'`},
+		{Eq(&Column{"", "a"}, StringLiteral("x")), `"a" = 'x'`},
+		{Func("coalesce")(&Column{"", "a"}, Null), `coalesce("a",NULL)`},
+	}
+	testMany(t, cases)
+
+	c := NewCompiler(&Postgres{})
+	for _, f := range []float64{math.NaN(), math.Inf(1), math.Inf(-1)} {
+		_, err := c.Compile(FloatLiteral(f))
+		testEqual(t, err, ErrNonFiniteFloat)
+	}
+	testUnsupported(t, &Postgres{}, StringLiteral("a\x00"), "NUL in string literal")
+}
+
+func TestLiteralDialects(t *testing.T) {
+	ts := TimeLiteral(time.Date(2019, 3, 4, 5, 6, 7, 0, time.UTC))
+	cases := []struct {
+		dialect Dialect
+		in      Node
+		out     string
+	}{
+		{&MySQL{}, StringLiteral(`it's a\b`), `'it''s a\\b'`},
+		{&MySQL{}, StringLiteral("a\x00"), `'a\0'`},
+		{&MySQL{}, &BytesLiteral{[]byte{0x01}}, `X'01'`},
+		{&MySQL{}, BoolLiteral(true), `TRUE`},
+		{&MySQL{}, ts, `TIMESTAMP '2019-03-04 05:06:07'`},
+		{&SQLite{}, StringLiteral(`it's a\b`), `'it''s a\b'`},
+		{&SQLite{}, &BytesLiteral{[]byte{0x01}}, `X'01'`},
+		{&SQLite{}, BoolLiteral(true), `1`},
+		{&SQLite{}, BoolLiteral(false), `0`},
+		{&SQLite{}, ts, `'2019-03-04 05:06:07'`},
+		{&SQLServer{}, StringLiteral(`it's a\b`), `N'it''s a\b'`},
+		{&SQLServer{}, &BytesLiteral{[]byte{0xbe, 0xef}}, `0xbeef`},
+		{&SQLServer{}, BoolLiteral(false), `0`},
+		{&SQLServer{}, ts, `CAST('2019-03-04T05:06:07' AS DATETIME2)`},
+		{&SQLServer{}, Null, `NULL`},
+	}
+	for _, case_ := range cases {
+		testCompileDialect(t, case_.dialect, case_.in, case_.out)
+	}
+	testUnsupported(t, &SQLite{}, StringLiteral("a\x00b"), "NUL in string literal")
+	testUnsupported(t, &SQLServer{}, StringLiteral("a\x00b"), "NUL in string literal")
+}
+
+func TestBytesLiteralTransform(t *testing.T) {
+	e := Eq(&Column{"", "b"}, &BytesLiteral{[]byte{0x01}})
+	if e.Transform(NewCompiler(&Postgres{})) != Node(e) {
+		t.Errorf("BinaryOperator was copied without changes")
+	}
+}
@@ -97,3 +97,55 @@ func (m *Mapper) init(rows *sql.Rows, outputRowType reflect.Type) error {
 	m.indexPaths = indexPaths
 	return nil
 }
+
+// exportedFields returns the names and the indices of
+// the exported fields of the struct type t.
+func exportedFields(t reflect.Type) ([]string, []int) {
+	var names []string
+	var indices []int
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" || field.Anonymous {
+			continue
+		}
+		names = append(names, field.Name)
+		indices = append(indices, i)
+	}
+	return names, indices
+}
+
+// LabeledColumns returns a column of tableLabel for every exported field
+// of the struct v, labeled so that Mapper scans it into the field
+// of the same name of the struct field named outputField.
+//
+// Example
+//  type User struct {
+//  	ID   int
+//  	Name string
+//  }
+//  type OutputRow struct {
+//  	User User
+//  }
+//  columns, _ := LabeledColumns("User", "u", User{})
+//  // "u"."ID" "User_ID","u"."Name" "User_Name"
+func LabeledColumns(outputField string, tableLabel string, v interface{}) ([]*LabeledColumn, error) {
+	t := reflect.TypeOf(v)
+	if t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil, ErrOutputRowFieldMustBeStruct
+	}
+	names, _ := exportedFields(t)
+	if len(names) <= 0 {
+		return nil, ErrZeroLength
+	}
+	columns := make([]*LabeledColumn, len(names))
+	for i, name := range names {
+		columns[i] = &LabeledColumn{
+			Expr:  &Column{tableLabel, name},
+			Label: outputField + "_" + name,
+		}
+	}
+	return columns, nil
+}
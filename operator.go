@@ -1,5 +1,9 @@
 package flexsql
 
+import (
+	"fmt"
+)
+
 type OperatorType uint
 
 const (
@@ -32,6 +36,13 @@ const (
 	OpNot
 	OpAnd
 	OpOr
+	OpConcat
+	OpIs
+	OpIsNot
+	OpGlob
+	OpNotGlob
+	OpExists
+	OpNotExists
 )
 
 type Associativity uint
@@ -129,8 +140,13 @@ func (u *UnaryOperator) Transform(c *Compiler) Node {
 			}
 		}
 	}
-	u.Expr = (u.Expr.Transform(c)).(Expr)
-	return u
+	expr, changed := transformNode(u.Expr, c)
+	if !changed {
+		return u
+	}
+	copied := *u
+	copied.Expr = expr
+	return &copied
 }
 
 func (u *UnaryOperator) Stringify(c *Compiler) error {
@@ -155,7 +171,7 @@ func (u *UnaryOperator) Stringify(c *Compiler) error {
 				return err
 			}
 		} else {
-			if err := e.Stringify(c); err != nil {
+			if err := c.WriteNode(e); err != nil {
 				return err
 			}
 		}
@@ -220,12 +236,38 @@ func (b *BinaryOperator) negate() Expr {
 }
 
 func (b *BinaryOperator) Transform(c *Compiler) Node {
-	b.Left = (b.Left.Transform(c)).(Expr)
-	b.Right = (b.Right.Transform(c)).(Expr)
-	return b
+	left, leftChanged := transformNode(b.Left, c)
+	right, rightChanged := transformNode(b.Right, c)
+	if !leftChanged && !rightChanged {
+		return b
+	}
+	copied := *b
+	copied.Left = left
+	copied.Right = right
+	return &copied
+}
+
+// operatorFeature returns the Feature required by op, if any.
+func operatorFeature(op OperatorType) (Feature, bool) {
+	switch op {
+	case OpILike, OpNotILike:
+		return FeatureILike, true
+	case OpConcat:
+		return FeatureConcat, true
+	case OpIs, OpIsNot:
+		return FeatureIs, true
+	case OpGlob, OpNotGlob:
+		return FeatureGlob, true
+	}
+	return 0, false
 }
 
 func (b *BinaryOperator) Stringify(c *Compiler) error {
+	if f, ok := operatorFeature(b.Type); ok {
+		if err := c.requireFeature(f); err != nil {
+			return err
+		}
+	}
 	assoc, err := resolveOperatorAssociativity(b, c)
 	if err != nil {
 		return err
@@ -238,7 +280,7 @@ func (b *BinaryOperator) Stringify(c *Compiler) error {
 	handleSide := func(e Expr, targetAssoc Associativity) error {
 		op, ok := e.(operator)
 		if !ok {
-			return e.Stringify(c)
+			return c.WriteNode(e)
 		}
 		theirPrecedence, err := resolveOperatorPrecedence(op, c)
 		if err != nil {
@@ -250,7 +292,7 @@ func (b *BinaryOperator) Stringify(c *Compiler) error {
 		if needParen {
 			return stringifyParen(op, c)
 		}
-		return op.Stringify(c)
+		return c.WriteNode(op)
 	}
 
 	if err := handleSide(b.Left, RightAssociative); err != nil {
@@ -311,10 +353,17 @@ func (t *TernaryOperator) negate() Expr {
 }
 
 func (t *TernaryOperator) Transform(c *Compiler) Node {
-	t.Expr1 = (t.Expr1.Transform(c)).(Expr)
-	t.Expr2 = (t.Expr2.Transform(c)).(Expr)
-	t.Expr3 = (t.Expr3.Transform(c)).(Expr)
-	return t
+	expr1, changed1 := transformNode(t.Expr1, c)
+	expr2, changed2 := transformNode(t.Expr2, c)
+	expr3, changed3 := transformNode(t.Expr3, c)
+	if !changed1 && !changed2 && !changed3 {
+		return t
+	}
+	copied := *t
+	copied.Expr1 = expr1
+	copied.Expr2 = expr2
+	copied.Expr3 = expr3
+	return &copied
 }
 
 func (t *TernaryOperator) Stringify(c *Compiler) error {
@@ -326,7 +375,7 @@ func (t *TernaryOperator) Stringify(c *Compiler) error {
 	handleExpr := func(e Expr) error {
 		op, ok := e.(operator)
 		if !ok {
-			return e.Stringify(c)
+			return c.WriteNode(e)
 		}
 		theirPrecedence, err := resolveOperatorPrecedence(op, c)
 		if err != nil {
@@ -336,7 +385,7 @@ func (t *TernaryOperator) Stringify(c *Compiler) error {
 		if needParen {
 			return stringifyParen(op, c)
 		}
-		return op.Stringify(c)
+		return c.WriteNode(op)
 	}
 
 	if err := handleExpr(t.Expr1); err != nil {
@@ -605,6 +654,79 @@ func NotILike(left, right Expr) *BinaryOperator {
 	}
 }
 
+func Concat(left, right Expr) *BinaryOperator {
+	return &BinaryOperator{
+		Type:   OpConcat,
+		Symbol: "||",
+		Left:   left,
+		Right:  right,
+	}
+}
+
+func Is(left, right Expr) *BinaryOperator {
+	return &BinaryOperator{
+		Type:          OpIs,
+		Symbol:        "IS",
+		NegatedType:   OpIsNot,
+		NegatedSymbol: "IS NOT",
+		Left:          left,
+		Right:         right,
+	}
+}
+
+func IsNot(left, right Expr) *BinaryOperator {
+	return &BinaryOperator{
+		Type:          OpIsNot,
+		Symbol:        "IS NOT",
+		NegatedType:   OpIs,
+		NegatedSymbol: "IS",
+		Left:          left,
+		Right:         right,
+	}
+}
+
+func Glob(left, right Expr) *BinaryOperator {
+	return &BinaryOperator{
+		Type:          OpGlob,
+		Symbol:        "GLOB",
+		NegatedType:   OpNotGlob,
+		NegatedSymbol: "NOT GLOB",
+		Left:          left,
+		Right:         right,
+	}
+}
+
+func NotGlob(left, right Expr) *BinaryOperator {
+	return &BinaryOperator{
+		Type:          OpNotGlob,
+		Symbol:        "NOT GLOB",
+		NegatedType:   OpGlob,
+		NegatedSymbol: "GLOB",
+		Left:          left,
+		Right:         right,
+	}
+}
+
+func Exists(q Query) *UnaryOperator {
+	return &UnaryOperator{
+		Type:          OpExists,
+		Symbol:        "EXISTS",
+		NegatedType:   OpNotExists,
+		NegatedSymbol: "NOT EXISTS",
+		Expr:          Subquery(q),
+	}
+}
+
+func NotExists(q Query) *UnaryOperator {
+	return &UnaryOperator{
+		Type:          OpNotExists,
+		Symbol:        "NOT EXISTS",
+		NegatedType:   OpExists,
+		NegatedSymbol: "EXISTS",
+		Expr:          Subquery(q),
+	}
+}
+
 func Between(expr1, expr2, expr3 Expr) *TernaryOperator {
 	return &TernaryOperator{
 		Type:           OpBetween,
@@ -632,3 +754,314 @@ func NotBetween(expr1, expr2, expr3 Expr) *TernaryOperator {
 		Expr3:          expr3,
 	}
 }
+
+var comparisonSymbols = map[OperatorType]string{
+	OpEq:    "=",
+	OpNotEq: "<>",
+	OpLt:    "<",
+	OpLte:   "<=",
+	OpGt:    ">",
+	OpGte:   ">=",
+}
+
+// negatedComparisons maps every comparison to the one
+// returning the negated result, including for NULL operands.
+var negatedComparisons = map[OperatorType]OperatorType{
+	OpEq:    OpNotEq,
+	OpNotEq: OpEq,
+	OpLt:    OpGte,
+	OpLte:   OpGt,
+	OpGt:    OpLte,
+	OpGte:   OpLt,
+}
+
+// QuantifiedOperator compares Left with every row of a subquery or
+// every element of an array, and holds if the comparison holds for
+// any of them, or for all of them if All is set.
+// Negating it negates the comparison and swaps ANY and ALL.
+type QuantifiedOperator struct {
+	Type  OperatorType
+	All   bool
+	Left  Expr
+	Right Expr
+}
+
+func newQuantifiedOperator(op OperatorType, all bool, left, right Expr) *QuantifiedOperator {
+	if _, ok := comparisonSymbols[op]; !ok {
+		panic(fmt.Sprintf("illegal quantified comparison: %v", op))
+	}
+	return &QuantifiedOperator{
+		Type:  op,
+		All:   all,
+		Left:  left,
+		Right: right,
+	}
+}
+
+// Any returns left op ANY(right), where op is one of OpEq, OpNotEq,
+// OpLt, OpLte, OpGt and OpGte, and right is a Query or an array.
+func Any(op OperatorType, left, right Expr) *QuantifiedOperator {
+	return newQuantifiedOperator(op, false, left, right)
+}
+
+// All returns left op ALL(right), where op is one of OpEq, OpNotEq,
+// OpLt, OpLte, OpGt and OpGte, and right is a Query or an array.
+func All(op OperatorType, left, right Expr) *QuantifiedOperator {
+	return newQuantifiedOperator(op, true, left, right)
+}
+
+func (q *QuantifiedOperator) precedence() uint {
+	return 0
+}
+
+func (q *QuantifiedOperator) associativity() Associativity {
+	return 0
+}
+
+func (q *QuantifiedOperator) operatorType() OperatorType {
+	return q.Type
+}
+
+func (q *QuantifiedOperator) negatable() bool {
+	return true
+}
+
+func (q *QuantifiedOperator) negate() Expr {
+	return &QuantifiedOperator{
+		Type:  negatedComparisons[q.Type],
+		All:   !q.All,
+		Left:  q.Left,
+		Right: q.Right,
+	}
+}
+
+func (q *QuantifiedOperator) Transform(c *Compiler) Node {
+	left, leftChanged := transformNode(q.Left, c)
+	right, rightChanged := transformNode(q.Right, c)
+	if !leftChanged && !rightChanged {
+		return q
+	}
+	copied := *q
+	copied.Left = left
+	copied.Right = right
+	return &copied
+}
+
+func (q *QuantifiedOperator) Stringify(c *Compiler) error {
+	right := q.Right
+	if s, ok := right.(*SubqueryExpr); ok {
+		right = s.Query
+	}
+	if _, ok := right.(Query); ok {
+		if err := c.requireFeature(FeatureQuantifiedSubquery); err != nil {
+			return err
+		}
+	} else if err := c.requireFeature(FeatureQuantifiedArray); err != nil {
+		return err
+	}
+	ourPrecedence, err := resolveOperatorPrecedence(q, c)
+	if err != nil {
+		return err
+	}
+
+	if op, ok := q.Left.(operator); ok {
+		theirPrecedence, err := resolveOperatorPrecedence(op, c)
+		if err != nil {
+			return err
+		}
+		if theirPrecedence <= ourPrecedence {
+			if err := stringifyParen(op, c); err != nil {
+				return err
+			}
+		} else if err := c.WriteNode(op); err != nil {
+			return err
+		}
+	} else if err := c.WriteNode(q.Left); err != nil {
+		return err
+	}
+	c.WriteVerbatim(" " + comparisonSymbols[q.Type] + " ")
+	if q.All {
+		c.WriteVerbatim("ALL")
+	} else {
+		c.WriteVerbatim("ANY")
+	}
+	return stringifyParen(right, c)
+}
+
+// InList is IN or NOT IN with either a subquery or a list of expressions.
+// Unlike In with an empty Tuple, an empty list compiles to a constant:
+// FALSE for IN and TRUE for NOT IN.
+// Slice is set by InSlice to the name of the placeholder
+// of the slice whose elements are Values.
+type InList struct {
+	Negated bool
+	Left    Expr
+	Query   Query
+	Values  []Expr
+	Slice   string
+}
+
+func InValues(left Expr, values ...Expr) *InList {
+	return &InList{
+		Left:   left,
+		Values: values,
+	}
+}
+
+func NotInValues(left Expr, values ...Expr) *InList {
+	return &InList{
+		Negated: true,
+		Left:    left,
+		Values:  values,
+	}
+}
+
+// InSlice returns left IN a slice of the given length bound to
+// the placeholder name. The slice is expanded into placeholders named
+// name_1, name_2 and so on, among which CompiledQuery.Bind spreads
+// the elements of the slice, so Compile fails with ErrSliceLengthConflict
+// if name is used with different lengths. With WithArrayBinding, dialects
+// supporting FeatureQuantifiedArray compile it to left = ANY(name) instead
+// and bind the slice as it is, whatever its length.
+// Some drivers require the slice to be wrapped to be bound as an array,
+// e.g. with pq.Array.
+func InSlice(left Expr, name string, length int) *InList {
+	_, values := Placeholders(name+"_", length)
+	return &InList{
+		Left:   left,
+		Values: values,
+		Slice:  name,
+	}
+}
+
+func NotInSlice(left Expr, name string, length int) *InList {
+	in := InSlice(left, name, length)
+	in.Negated = true
+	return in
+}
+
+func InQuery(left Expr, q Query) *InList {
+	return &InList{
+		Left:  left,
+		Query: q,
+	}
+}
+
+func NotInQuery(left Expr, q Query) *InList {
+	return &InList{
+		Negated: true,
+		Left:    left,
+		Query:   q,
+	}
+}
+
+// IsEmpty reports whether i compiles to a constant.
+func (i *InList) IsEmpty() bool {
+	return i.Query == nil && len(i.Values) <= 0
+}
+
+func (i *InList) precedence() uint {
+	return 0
+}
+
+func (i *InList) associativity() Associativity {
+	return 0
+}
+
+func (i *InList) operatorType() OperatorType {
+	if i.Negated {
+		return OpNotIn
+	}
+	return OpIn
+}
+
+func (i *InList) negatable() bool {
+	return true
+}
+
+func (i *InList) negate() Expr {
+	copied := *i
+	copied.Negated = !i.Negated
+	return &copied
+}
+
+func (i *InList) Transform(c *Compiler) Node {
+	if i.Slice != "" {
+		if c.bindsArrays() {
+			if i.Negated {
+				return All(OpNotEq, i.Left, Placeholder(i.Slice)).Transform(c)
+			}
+			return Any(OpEq, i.Left, Placeholder(i.Slice)).Transform(c)
+		}
+		c.registerSlice(i.Slice, len(i.Values))
+	}
+
+	copied := *i
+	changed := false
+
+	if n, ok := transformNode(i.Left, c); ok {
+		copied.Left = n
+		changed = true
+	}
+	if i.Query != nil {
+		if n, ok := transformNode(i.Query, c); ok {
+			copied.Query = n.(Query)
+			changed = true
+		}
+	}
+	if values, ok := transformExprs(i.Values, c); ok {
+		copied.Values = values
+		changed = true
+	}
+
+	if !changed {
+		return i
+	}
+	return &copied
+}
+
+func (i *InList) Stringify(c *Compiler) error {
+	if i.IsEmpty() {
+		return c.WriteNode(BoolLiteral(i.Negated))
+	}
+	assoc, err := resolveOperatorAssociativity(i, c)
+	if err != nil {
+		return err
+	}
+	ourPrecedence, err := resolveOperatorPrecedence(i, c)
+	if err != nil {
+		return err
+	}
+
+	if op, ok := i.Left.(operator); ok {
+		theirPrecedence, err := resolveOperatorPrecedence(op, c)
+		if err != nil {
+			return err
+		}
+		needParen := theirPrecedence < ourPrecedence ||
+			theirPrecedence == ourPrecedence && assoc != LeftAssociative
+		if needParen {
+			if err := stringifyParen(op, c); err != nil {
+				return err
+			}
+		} else if err := c.WriteNode(op); err != nil {
+			return err
+		}
+	} else if err := c.WriteNode(i.Left); err != nil {
+		return err
+	}
+	if i.Negated {
+		c.WriteVerbatim(" NOT IN (")
+	} else {
+		c.WriteVerbatim(" IN (")
+	}
+	if i.Query != nil {
+		if err := c.WriteNode(i.Query); err != nil {
+			return err
+		}
+	} else if err := stringifyCommaSeparated(i.Values, c); err != nil {
+		return err
+	}
+	c.WriteVerbatim(")")
+	return nil
+}
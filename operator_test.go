@@ -1,6 +1,7 @@
 package flexsql
 
 import (
+	"fmt"
 	"testing"
 )
 
@@ -141,3 +142,105 @@ func TestBetween(t *testing.T) {
 	}
 	testMany(t, cases)
 }
+
+func TestConcat(t *testing.T) {
+	f := literal("f")
+	cases := []compileTest{
+		{Concat(f, f), "f || f"},
+		{Concat(Concat(f, f), f), "f || f || f"},
+		{Concat(f, Concat(f, f)), "f || (f || f)"},
+		{Concat(Add(f, f), f), "f + f || f"},
+		{Add(Concat(f, f), f), "(f || f) + f"},
+		{Like(Concat(f, f), f), "f || f LIKE f"},
+	}
+	testMany(t, cases)
+}
+
+func TestExists(t *testing.T) {
+	a := literal("a")
+	q := &SelectStmt{Columns: []*LabeledColumn{{a, "a"}}}
+	cases := []compileTest{
+		{Exists(q), `EXISTS (SELECT a "a")`},
+		{NotExists(q), `NOT EXISTS (SELECT a "a")`},
+		{Not(Exists(q)), `NOT EXISTS (SELECT a "a")`},
+		{Not(NotExists(q)), `EXISTS (SELECT a "a")`},
+		{And(Exists(q), NotExists(q)), `EXISTS (SELECT a "a") AND NOT EXISTS (SELECT a "a")`},
+		{Eq(Exists(q), a), `EXISTS (SELECT a "a") = a`},
+		{Eq(NotExists(q), a), `(NOT EXISTS (SELECT a "a")) = a`},
+		{IsNull(NotExists(q)), `(NOT EXISTS (SELECT a "a")) IS NULL`},
+		{Or(NotExists(q), a), `NOT EXISTS (SELECT a "a") OR a`},
+	}
+	testMany(t, cases)
+	testCompileDialect(t, &MySQL{}, Not(Exists(q)), "NOT EXISTS (SELECT a `a`)")
+	testCompileDialect(t, &SQLite{}, Eq(Exists(q), a), `EXISTS (SELECT a "a") = a`)
+	testCompileDialect(t, &SQLServer{}, Not(Exists(q)), `NOT EXISTS (SELECT a [a])`)
+}
+
+func TestQuantifiedOperator(t *testing.T) {
+	a := literal("a")
+	q := &SelectStmt{Columns: []*LabeledColumn{{a, "a"}}}
+	arr := Placeholder("arr")
+	cases := []compileTest{
+		{Any(OpEq, a, q), `a = ANY(SELECT a "a")`},
+		{All(OpGt, a, q), `a > ALL(SELECT a "a")`},
+		{Any(OpLt, a, Subquery(q)), `a < ANY(SELECT a "a")`},
+		{Any(OpEq, a, arr), `a = ANY($1)`},
+		{Not(Any(OpEq, a, arr)), `a <> ALL($1)`},
+		{Not(All(OpLt, a, arr)), `a >= ANY($1)`},
+		{Not(All(OpLte, a, arr)), `a > ANY($1)`},
+		{Not(Not(Any(OpGte, a, arr))), `a >= ANY($1)`},
+		{Any(OpEq, Add(a, a), arr), `a + a = ANY($1)`},
+		{Any(OpEq, Eq(a, a), arr), `(a = a) = ANY($1)`},
+		{And(Any(OpEq, a, arr), Any(OpNotEq, a, arr)), `a = ANY($1) AND a <> ANY($2)`},
+	}
+	testMany(t, cases)
+
+	testCompileDialect(t, &MySQL{}, Not(Any(OpEq, a, q)), "a <> ALL(SELECT a `a`)")
+	testCompileDialect(t, &SQLServer{}, All(OpGte, a, q), `a >= ALL(SELECT a [a])`)
+	testUnsupported(t, &MySQL{}, Any(OpEq, a, arr), "ANY/ALL with array")
+	testUnsupported(t, &SQLServer{}, Any(OpEq, a, arr), "ANY/ALL with array")
+	testUnsupported(t, &SQLite{}, Any(OpEq, a, q), "ANY/ALL")
+	testPanic(t, func() { Any(OpLike, a, arr) }, fmt.Sprintf("illegal quantified comparison: %v", OpLike))
+}
+
+func TestInList(t *testing.T) {
+	a := literal("a")
+	q := &SelectStmt{Columns: []*LabeledColumn{{a, "a"}}}
+	_, none := Placeholders("id", 0)
+	_, two := Placeholders("id", 2)
+	cases := []compileTest{
+		{InValues(a, two...), `a IN ($1,$2)`},
+		{NotInValues(a, two...), `a NOT IN ($1,$2)`},
+		{Not(InValues(a, two...)), `a NOT IN ($1,$2)`},
+		{Not(NotInValues(a, two...)), `a IN ($1,$2)`},
+		{InQuery(a, q), `a IN (SELECT a "a")`},
+		{NotInQuery(a, Union(q, q)), `a NOT IN (SELECT a "a" UNION SELECT a "a")`},
+		{Not(InQuery(a, q)), `a NOT IN (SELECT a "a")`},
+		{InValues(a, none...), `FALSE`},
+		{NotInValues(a, none...), `TRUE`},
+		{Not(InValues(a)), `TRUE`},
+		{And(InValues(a), a), `FALSE AND a`},
+		{InValues(Add(a, a), a), `a + a IN (a)`},
+		{InValues(Eq(a, a), a), `(a = a) IN (a)`},
+		{InValues(Not(Not(a)), Not(Not(a))), `a IN (a)`},
+	}
+	testMany(t, cases)
+
+	dialectCases := []struct {
+		dialect Dialect
+		in      Node
+		out     string
+	}{
+		{&MySQL{}, InValues(a), `FALSE`},
+		{&MySQL{}, InValues(a, two...), `a IN (?,?)`},
+		{&SQLite{}, InValues(a), `0`},
+		{&SQLite{}, NotInValues(a), `1`},
+		{&SQLServer{}, InValues(a), `1 = 0`},
+		{&SQLServer{}, Not(InValues(a)), `1 = 1`},
+		{&SQLServer{}, And(InValues(a), Eq(a, a)), `1 = 0 AND a = a`},
+		{&SQLServer{}, NotInValues(a, two...), `a NOT IN (@p1,@p2)`},
+	}
+	for _, case_ := range dialectCases {
+		testCompileDialect(t, case_.dialect, case_.in, case_.out)
+	}
+}
@@ -0,0 +1,206 @@
+package flexsql
+
+import (
+	"fmt"
+)
+
+// Query is a node producing rows, either *SelectStmt or *SetOperation.
+type Query interface {
+	Node
+	private()
+}
+
+type SetOperationType int
+
+const (
+	_ SetOperationType = iota
+	SetOpUnion
+	SetOpIntersect
+	SetOpExcept
+)
+
+func (t SetOperationType) String() string {
+	switch t {
+	case SetOpUnion:
+		return "UNION"
+	case SetOpIntersect:
+		return "INTERSECT"
+	case SetOpExcept:
+		return "EXCEPT"
+	}
+	return fmt.Sprintf("SetOperationType(%d)", int(t))
+}
+
+// precedence follows the SQL standard,
+// where INTERSECT binds tighter than UNION and EXCEPT.
+func (t SetOperationType) precedence() int {
+	if t == SetOpIntersect {
+		return 2
+	}
+	return 1
+}
+
+// SetOperation combines the rows of two queries.
+// OrderByClause, LimitClause and OffsetClause apply to the combined rows.
+type SetOperation struct {
+	Type          SetOperationType
+	All           bool
+	Left          Query
+	Right         Query
+	OrderByClause *OrderByClause
+	LimitClause   *LimitClause
+	OffsetClause  *OffsetClause
+}
+
+// LabeledSetOperation is a SetOperation used as a FromClauseItem.
+type LabeledSetOperation struct {
+	SetOperation *SetOperation
+	Label        string
+}
+
+func (l *LabeledSetOperation) Transform(c *Compiler) Node {
+	setOperation, changed := transformNode(l.SetOperation, c)
+	if !changed {
+		return l
+	}
+	return &LabeledSetOperation{
+		SetOperation: setOperation.(*SetOperation),
+		Label:        l.Label,
+	}
+}
+
+func (l *LabeledSetOperation) Stringify(c *Compiler) error {
+	c.WriteVerbatim("(")
+	if err := c.WriteNode(l.SetOperation); err != nil {
+		return err
+	}
+	c.WriteVerbatim(") ")
+	c.WriteIdentifier(l.Label)
+	return nil
+}
+
+func newSetOperation(t SetOperationType, all bool, left, right Query) *SetOperation {
+	return &SetOperation{
+		Type:  t,
+		All:   all,
+		Left:  left,
+		Right: right,
+	}
+}
+
+func Union(left, right Query) *SetOperation {
+	return newSetOperation(SetOpUnion, false, left, right)
+}
+
+func UnionAll(left, right Query) *SetOperation {
+	return newSetOperation(SetOpUnion, true, left, right)
+}
+
+func Intersect(left, right Query) *SetOperation {
+	return newSetOperation(SetOpIntersect, false, left, right)
+}
+
+func IntersectAll(left, right Query) *SetOperation {
+	return newSetOperation(SetOpIntersect, true, left, right)
+}
+
+func Except(left, right Query) *SetOperation {
+	return newSetOperation(SetOpExcept, false, left, right)
+}
+
+func ExceptAll(left, right Query) *SetOperation {
+	return newSetOperation(SetOpExcept, true, left, right)
+}
+
+func (s *SetOperation) private() {
+}
+
+func (s *SetOperation) Transform(c *Compiler) Node {
+	copied := *s
+	changed := false
+
+	if n, ok := transformNode(s.Left, c); ok {
+		copied.Left = n.(Query)
+		changed = true
+	}
+	if n, ok := transformNode(s.Right, c); ok {
+		copied.Right = n.(Query)
+		changed = true
+	}
+	if s.OrderByClause != nil {
+		c.outputOrderBy = true
+		if n, ok := transformNode(s.OrderByClause, c); ok {
+			copied.OrderByClause = n.(*OrderByClause)
+			changed = true
+		}
+	}
+	if s.LimitClause != nil {
+		if n, ok := transformNode(s.LimitClause, c); ok {
+			copied.LimitClause = n.(*LimitClause)
+			changed = true
+		}
+	}
+	if s.OffsetClause != nil {
+		if n, ok := transformNode(s.OffsetClause, c); ok {
+			copied.OffsetClause = n.(*OffsetClause)
+			changed = true
+		}
+	}
+
+	if !changed {
+		return s
+	}
+	return &copied
+}
+
+// needsParen reports whether operand has to be parenthesized
+// to be an operand of s. A SELECT statement does if it has clauses
+// that would otherwise apply to the combined rows, and a set operation
+// does unless it binds tighter than s or is the left operand of
+// the same precedence.
+func (s *SetOperation) needsParen(operand Query, right bool) bool {
+	switch v := operand.(type) {
+	case *SelectStmt:
+		return v.WithClause != nil || v.OrderByClause != nil || v.LimitClause != nil || v.OffsetClause != nil
+	case *SetOperation:
+		if v.OrderByClause != nil || v.LimitClause != nil || v.OffsetClause != nil {
+			return true
+		}
+		p := v.Type.precedence()
+		sp := s.Type.precedence()
+		if right {
+			return p <= sp
+		}
+		return p < sp
+	}
+	return true
+}
+
+func (s *SetOperation) stringifyOperand(operand Query, right bool, c *Compiler) error {
+	if s.needsParen(operand, right) {
+		return stringifyParen(operand, c)
+	}
+	return c.WriteNode(operand)
+}
+
+func (s *SetOperation) Stringify(c *Compiler) error {
+	if err := s.stringifyOperand(s.Left, false, c); err != nil {
+		return err
+	}
+	c.writeClauseSeparator()
+	c.WriteVerbatim(s.Type.String())
+	if s.All {
+		c.WriteVerbatim(" ALL")
+	}
+	c.writeClauseSeparator()
+	if err := s.stringifyOperand(s.Right, true, c); err != nil {
+		return err
+	}
+	if s.OrderByClause != nil {
+		c.writeClauseSeparator()
+		if err := c.WriteNode(s.OrderByClause); err != nil {
+			return err
+		}
+	}
+	return stringifyRowLimit(c, s.OrderByClause, s.LimitClause, s.OffsetClause)
+}
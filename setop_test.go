@@ -0,0 +1,89 @@
+package flexsql
+
+import (
+	"testing"
+)
+
+func TestSetOperation(t *testing.T) {
+	selectFrom := func(table string) *SelectStmt {
+		return &SelectStmt{
+			Columns:    []*LabeledColumn{{&Column{"t", "id"}, "id"}},
+			FromClause: &FromClause{&FromClauseItem{TableRef: &LabeledTable{"", table, "t"}}},
+		}
+	}
+	a := selectFrom("a")
+	b := selectFrom("b")
+	c := selectFrom("c")
+	limited := selectFrom("c")
+	limited.OrderByClause = OrderBy(Asc(&Column{"t", "id"}))
+	limited.LimitClause = &LimitClause{Placeholder("limit")}
+
+	sa := `SELECT "t"."id" "id" FROM "a" "t"`
+	sb := `SELECT "t"."id" "id" FROM "b" "t"`
+	sc := `SELECT "t"."id" "id" FROM "c" "t"`
+
+	sorted := UnionAll(a, b)
+	sorted.OrderByClause = OrderBy(Desc(&Column{"", "id"}))
+	sorted.LimitClause = &LimitClause{Placeholder("limit")}
+
+	cases := []compileTest{
+		{Union(a, b), sa + ` UNION ` + sb},
+		{UnionAll(a, b), sa + ` UNION ALL ` + sb},
+		{Intersect(a, b), sa + ` INTERSECT ` + sb},
+		{IntersectAll(a, b), sa + ` INTERSECT ALL ` + sb},
+		{Except(a, b), sa + ` EXCEPT ` + sb},
+		{ExceptAll(a, b), sa + ` EXCEPT ALL ` + sb},
+		{Union(Union(a, b), c), sa + ` UNION ` + sb + ` UNION ` + sc},
+		{Union(a, Union(b, c)), sa + ` UNION (` + sb + ` UNION ` + sc + `)`},
+		{Union(a, Intersect(b, c)), sa + ` UNION ` + sb + ` INTERSECT ` + sc},
+		{Intersect(Union(a, b), c), `(` + sa + ` UNION ` + sb + `) INTERSECT ` + sc},
+		{Except(Intersect(a, b), c), sa + ` INTERSECT ` + sb + ` EXCEPT ` + sc},
+		{Union(a, limited), sa + ` UNION (` + sc + ` ORDER BY "t"."id" LIMIT $1)`},
+		{sorted, sa + ` UNION ALL ` + sb + ` ORDER BY "id" DESC LIMIT $1`},
+		{Union(sorted, c), `(` + sa + ` UNION ALL ` + sb + ` ORDER BY "id" DESC LIMIT $1) UNION ` + sc},
+		{
+			&SelectStmt{
+				Columns:    []*LabeledColumn{{&Column{"u", "id"}, "id"}},
+				FromClause: &FromClause{&FromClauseItem{SetOperation: &LabeledSetOperation{Union(a, b), "u"}}},
+			},
+			`SELECT "u"."id" "id" FROM (` + sa + ` UNION ` + sb + `) "u"`,
+		},
+		{
+			&InsertStmt{
+				Table:        &Table{"", "ids"},
+				SetOperation: Except(a, b),
+			},
+			`INSERT INTO "ids" ` + sa + ` EXCEPT ` + sb,
+		},
+		{
+			&SelectStmt{
+				WithClause: With(&CommonTableExpr{Name: "u", SetOperation: Union(a, b)}),
+				Columns:    []*LabeledColumn{{&Column{"u", "id"}, "id"}},
+				FromClause: &FromClause{&FromClauseItem{TableRef: &LabeledTable{Name: "u", Label: "u"}}},
+			},
+			`WITH "u" AS (` + sa + ` UNION ` + sb + `) SELECT "u"."id" "id" FROM "u" "u"`,
+		},
+	}
+	testMany(t, cases)
+}
+
+func TestSetOperationPrettyPrint(t *testing.T) {
+	a := &SelectStmt{Columns: []*LabeledColumn{{Placeholder("a"), "a"}}}
+	b := &SelectStmt{Columns: []*LabeledColumn{{Placeholder("b"), "b"}}}
+	q, err := NewCompiler(&Postgres{}, WithPrettyPrint()).Compile(UnionAll(a, b))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	testEqual(t, q.SQL(), "SELECT $1 \"a\"\nUNION ALL\nSELECT $2 \"b\"")
+}
+
+func TestSetOperationTransform(t *testing.T) {
+	f := Placeholder("f")
+	a := &SelectStmt{Columns: []*LabeledColumn{{Not(Not(f)), "f"}}}
+	b := &SelectStmt{Columns: []*LabeledColumn{{f, "f"}}}
+	u := Union(a, b)
+	testCompile(t, u, `SELECT $1 "f" UNION SELECT $2 "f"`)
+	testCompile(t, u, `SELECT $1 "f" UNION SELECT $2 "f"`)
+	testDeepEqual(t, u.Left, Query(a))
+	testDeepEqual(t, a.Columns[0].Expr, Not(Not(f)))
+}
@@ -29,14 +29,28 @@ func testMany(t *testing.T, cases []compileTest) {
 }
 
 func testCompile(t *testing.T, e Node, expected string) {
-	c := &Compiler{
-		dialect: &Postgres{},
-	}
+	testCompileDialect(t, &Postgres{}, e, expected)
+}
+
+func testCompileDialect(t *testing.T, d Dialect, e Node, expected string) {
+	c := NewCompiler(d)
 	actual, err := c.Compile(e)
 	if err != nil {
 		t.Errorf("unexpected error: %v", err)
+		return
+	}
+	testEqual(t, actual.SQL(), expected)
+}
+
+func testUnsupported(t *testing.T, d Dialect, e Node, construct string) {
+	c := NewCompiler(d)
+	_, err := c.Compile(e)
+	unsupported, ok := err.(*UnsupportedError)
+	if !ok {
+		t.Errorf("expected UnsupportedError but got: %v", err)
+		return
 	}
-	testEqual(t, actual, expected)
+	testEqual(t, unsupported.Construct, construct)
 }
 
 func testPanic(t *testing.T, op func(), value string) {
@@ -0,0 +1,300 @@
+package flexsql
+
+import (
+	"errors"
+)
+
+var ErrMissingFrameStart = errors.New("Missing frame start")
+
+type frameBoundType int
+
+const (
+	_ frameBoundType = iota
+	frameUnboundedPreceding
+	framePreceding
+	frameCurrentRow
+	frameFollowing
+	frameUnboundedFollowing
+)
+
+// FrameBound is the start or the end of a window frame.
+type FrameBound struct {
+	boundType frameBoundType
+	offset    Expr
+}
+
+func UnboundedPreceding() *FrameBound {
+	return &FrameBound{boundType: frameUnboundedPreceding}
+}
+
+func Preceding(offset Expr) *FrameBound {
+	return &FrameBound{
+		boundType: framePreceding,
+		offset:    offset,
+	}
+}
+
+func CurrentRow() *FrameBound {
+	return &FrameBound{boundType: frameCurrentRow}
+}
+
+func Following(offset Expr) *FrameBound {
+	return &FrameBound{
+		boundType: frameFollowing,
+		offset:    offset,
+	}
+}
+
+func UnboundedFollowing() *FrameBound {
+	return &FrameBound{boundType: frameUnboundedFollowing}
+}
+
+func (f *FrameBound) Transform(c *Compiler) Node {
+	if f.offset == nil {
+		return f
+	}
+	offset, changed := transformNode(f.offset, c)
+	if !changed {
+		return f
+	}
+	return &FrameBound{
+		boundType: f.boundType,
+		offset:    offset,
+	}
+}
+
+func (f *FrameBound) Stringify(c *Compiler) error {
+	switch f.boundType {
+	case frameUnboundedPreceding:
+		c.WriteVerbatim("UNBOUNDED PRECEDING")
+	case framePreceding:
+		if err := c.WriteNode(f.offset); err != nil {
+			return err
+		}
+		c.WriteVerbatim(" PRECEDING")
+	case frameCurrentRow:
+		c.WriteVerbatim("CURRENT ROW")
+	case frameFollowing:
+		if err := c.WriteNode(f.offset); err != nil {
+			return err
+		}
+		c.WriteVerbatim(" FOLLOWING")
+	case frameUnboundedFollowing:
+		c.WriteVerbatim("UNBOUNDED FOLLOWING")
+	}
+	return nil
+}
+
+type FrameUnits string
+
+const (
+	FrameRows   FrameUnits = "ROWS"
+	FrameRange  FrameUnits = "RANGE"
+	FrameGroups FrameUnits = "GROUPS"
+)
+
+// FrameClause is the frame of a window.
+// End may be nil to end the frame at the current row.
+type FrameClause struct {
+	Units FrameUnits
+	Start *FrameBound
+	End   *FrameBound
+}
+
+func Rows(start, end *FrameBound) *FrameClause {
+	return &FrameClause{FrameRows, start, end}
+}
+
+func Range(start, end *FrameBound) *FrameClause {
+	return &FrameClause{FrameRange, start, end}
+}
+
+func Groups(start, end *FrameBound) *FrameClause {
+	return &FrameClause{FrameGroups, start, end}
+}
+
+func (f *FrameClause) Transform(c *Compiler) Node {
+	copied := *f
+	changed := false
+
+	if f.Start != nil {
+		if n, ok := transformNode(f.Start, c); ok {
+			copied.Start = n.(*FrameBound)
+			changed = true
+		}
+	}
+	if f.End != nil {
+		if n, ok := transformNode(f.End, c); ok {
+			copied.End = n.(*FrameBound)
+			changed = true
+		}
+	}
+
+	if !changed {
+		return f
+	}
+	return &copied
+}
+
+func (f *FrameClause) Stringify(c *Compiler) error {
+	if f.Start == nil {
+		return ErrMissingFrameStart
+	}
+	if f.Units == FrameGroups {
+		if err := c.requireFeature(FeatureGroupsFrame); err != nil {
+			return err
+		}
+	}
+	c.WriteVerbatim(string(f.Units))
+	c.WriteVerbatim(" ")
+	if f.End == nil {
+		return c.WriteNode(f.Start)
+	}
+	c.WriteVerbatim("BETWEEN ")
+	if err := c.WriteNode(f.Start); err != nil {
+		return err
+	}
+	c.WriteVerbatim(" AND ")
+	return c.WriteNode(f.End)
+}
+
+// WindowSpec is the window of a window function,
+// which is written inside the parentheses of OVER and WINDOW.
+// ExistingWindow may name a window of the WINDOW clause to build upon.
+type WindowSpec struct {
+	ExistingWindow string
+	PartitionBy    []Expr
+	OrderByClause  *OrderByClause
+	FrameClause    *FrameClause
+}
+
+func (w *WindowSpec) Transform(c *Compiler) Node {
+	copied := *w
+	changed := false
+
+	if partitionBy, ok := transformExprs(w.PartitionBy, c); ok {
+		copied.PartitionBy = partitionBy
+		changed = true
+	}
+	if w.OrderByClause != nil {
+		if n, ok := transformNode(w.OrderByClause, c); ok {
+			copied.OrderByClause = n.(*OrderByClause)
+			changed = true
+		}
+	}
+	if w.FrameClause != nil {
+		if n, ok := transformNode(w.FrameClause, c); ok {
+			copied.FrameClause = n.(*FrameClause)
+			changed = true
+		}
+	}
+
+	if !changed {
+		return w
+	}
+	return &copied
+}
+
+func (w *WindowSpec) Stringify(c *Compiler) error {
+	separate := false
+	writeSeparator := func() {
+		if separate {
+			c.WriteVerbatim(" ")
+		}
+		separate = true
+	}
+	if w.ExistingWindow != "" {
+		writeSeparator()
+		c.WriteIdentifier(w.ExistingWindow)
+	}
+	if len(w.PartitionBy) > 0 {
+		writeSeparator()
+		c.WriteVerbatim("PARTITION BY ")
+		if err := stringifyCommaSeparated(w.PartitionBy, c); err != nil {
+			return err
+		}
+	}
+	if w.OrderByClause != nil {
+		writeSeparator()
+		if err := c.WriteNode(w.OrderByClause); err != nil {
+			return err
+		}
+	}
+	if w.FrameClause != nil {
+		writeSeparator()
+		if err := c.WriteNode(w.FrameClause); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// NamedWindow is a window defined in the WINDOW clause.
+type NamedWindow struct {
+	Name       string
+	WindowSpec *WindowSpec
+}
+
+func (n *NamedWindow) Transform(c *Compiler) Node {
+	spec, changed := transformNode(n.WindowSpec, c)
+	if !changed {
+		return n
+	}
+	return &NamedWindow{
+		Name:       n.Name,
+		WindowSpec: spec.(*WindowSpec),
+	}
+}
+
+func (n *NamedWindow) Stringify(c *Compiler) error {
+	c.WriteIdentifier(n.Name)
+	c.WriteVerbatim(" AS ")
+	return stringifyParen(n.WindowSpec, c)
+}
+
+type WindowClause struct {
+	Windows []*NamedWindow
+}
+
+func Window(first *NamedWindow, rest ...*NamedWindow) *WindowClause {
+	windows := make([]*NamedWindow, 1+len(rest))
+	windows[0] = first
+	for i, v := range rest {
+		windows[i+1] = v
+	}
+	return &WindowClause{windows}
+}
+
+func (w *WindowClause) Transform(c *Compiler) Node {
+	var windows []*NamedWindow
+	for i, v := range w.Windows {
+		t, windowChanged := transformNode(v, c)
+		if windowChanged && windows == nil {
+			windows = make([]*NamedWindow, len(w.Windows))
+			copy(windows, w.Windows)
+		}
+		if windows != nil {
+			windows[i] = t.(*NamedWindow)
+		}
+	}
+	if windows == nil {
+		return w
+	}
+	return &WindowClause{windows}
+}
+
+func (w *WindowClause) Stringify(c *Compiler) error {
+	if len(w.Windows) <= 0 {
+		return ErrZeroLength
+	}
+	c.WriteVerbatim("WINDOW ")
+	for i, v := range w.Windows {
+		if i > 0 {
+			c.WriteVerbatim(",")
+		}
+		if err := c.WriteNode(v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,88 @@
+package flexsql
+
+import (
+	"testing"
+)
+
+func TestFrameClause(t *testing.T) {
+	n := literal("3")
+	cases := []compileTest{
+		{Rows(UnboundedPreceding(), nil), "ROWS UNBOUNDED PRECEDING"},
+		{Rows(UnboundedPreceding(), CurrentRow()), "ROWS BETWEEN UNBOUNDED PRECEDING AND CURRENT ROW"},
+		{Range(Preceding(n), Following(n)), "RANGE BETWEEN 3 PRECEDING AND 3 FOLLOWING"},
+		{Groups(CurrentRow(), UnboundedFollowing()), "GROUPS BETWEEN CURRENT ROW AND UNBOUNDED FOLLOWING"},
+		{Rows(Preceding(Placeholder("n")), nil), "ROWS $1 PRECEDING"},
+	}
+	testMany(t, cases)
+	testUnsupported(t, &MySQL{}, Groups(CurrentRow(), nil), "GROUPS")
+	testUnsupported(t, &SQLServer{}, Groups(CurrentRow(), nil), "GROUPS")
+
+	_, err := NewCompiler(&Postgres{}).Compile(Rows(nil, CurrentRow()))
+	testEqual(t, err, ErrMissingFrameStart)
+}
+
+func TestWindowFunction(t *testing.T) {
+	f := &Column{"", "f"}
+	g := &Column{"", "g"}
+	rowNumber := Func("row_number")()
+	sum := Func("sum")
+	count := Func("count")
+
+	cases := []compileTest{
+		{rowNumber.Over(&WindowSpec{}), `row_number() OVER ()`},
+		{
+			rowNumber.Over(&WindowSpec{
+				PartitionBy:   []Expr{f, g},
+				OrderByClause: OrderBy(Desc(g)),
+			}),
+			`row_number() OVER (PARTITION BY "f","g" ORDER BY "g" DESC)`,
+		},
+		{
+			sum(f).Over(&WindowSpec{
+				OrderByClause: OrderBy(Asc(g)),
+				FrameClause:   Rows(UnboundedPreceding(), CurrentRow()),
+			}),
+			`sum("f") OVER (ORDER BY "g" ROWS BETWEEN UNBOUNDED PRECEDING AND CURRENT ROW)`,
+		},
+		{
+			sum(f).Over(&WindowSpec{
+				ExistingWindow: "w",
+				FrameClause:    Rows(UnboundedPreceding(), nil),
+			}),
+			`sum("f") OVER ("w" ROWS UNBOUNDED PRECEDING)`,
+		},
+		{sum(f).OverWindow("w"), `sum("f") OVER "w"`},
+		{sum(f).Over(&WindowSpec{}).OverWindow("w"), `sum("f") OVER "w"`},
+		{count(f).Filter(Gt(g, Placeholder("min"))), `count("f") FILTER (WHERE "g" > $1)`},
+		{
+			count(f).Filter(Not(Not(g))).OverWindow("w"),
+			`count("f") FILTER (WHERE "g") OVER "w"`,
+		},
+	}
+	testMany(t, cases)
+
+	testEqual(t, rowNumber.window == nil, true)
+	testUnsupported(t, &MySQL{}, count(f).Filter(g), "FILTER")
+	testUnsupported(t, &SQLServer{}, count(f).Filter(g), "FILTER")
+	testCompileDialect(t, &SQLite{}, count(f).Filter(g), `count("f") FILTER (WHERE "g")`)
+}
+
+func TestWindowClause(t *testing.T) {
+	f := &Column{"t", "f"}
+	g := &Column{"t", "g"}
+	sel := &SelectStmt{
+		Columns: []*LabeledColumn{
+			{Func("row_number")().OverWindow("w"), "T_RowNumber"},
+			{Func("sum")(f).Over(&WindowSpec{ExistingWindow: "w", FrameClause: Rows(UnboundedPreceding(), nil)}), "T_Total"},
+		},
+		FromClause:    &FromClause{&FromClauseItem{TableRef: &LabeledTable{"", "t", "t"}}},
+		WindowClause:  Window(&NamedWindow{"w", &WindowSpec{PartitionBy: []Expr{g}, OrderByClause: OrderBy(Asc(f))}}),
+		OrderByClause: OrderBy(Asc(g)),
+	}
+	testCompile(t, sel, `SELECT row_number() OVER "w" "T_RowNumber",sum("t"."f") OVER ("w" ROWS UNBOUNDED PRECEDING) "T_Total" `+
+		`FROM "t" "t" WINDOW "w" AS (PARTITION BY "t"."g" ORDER BY "t"."f") ORDER BY "t"."g"`)
+
+	sel.WindowClause = &WindowClause{}
+	_, err := NewCompiler(&Postgres{}).Compile(sel)
+	testEqual(t, err, ErrZeroLength)
+}